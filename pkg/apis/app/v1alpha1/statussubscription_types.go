@@ -0,0 +1,138 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StatusSubscriptionEvent is one kind of Subscription status transition an
+// external system can ask to be notified about.
+type StatusSubscriptionEvent string
+
+const (
+	StatusSubscriptionPropagated       StatusSubscriptionEvent = "Propagated"
+	StatusSubscriptionFailed           StatusSubscriptionEvent = "Failed"
+	StatusSubscriptionSubscribed       StatusSubscriptionEvent = "Subscribed"
+	StatusSubscriptionRollingUpdate    StatusSubscriptionEvent = "RollingUpdate"
+	StatusSubscriptionWebhookTriggered StatusSubscriptionEvent = "WebhookTriggered"
+	StatusSubscriptionDeleted          StatusSubscriptionEvent = "Deleted"
+)
+
+// StatusSubscriptionMatch selects the Subscription objects a
+// StatusSubscription cares about. At least one of Names or LabelSelector
+// should be set; an empty match selects every Subscription in Namespace (or
+// the StatusSubscription's own namespace, if Namespace is empty).
+type StatusSubscriptionMatch struct {
+	// Namespace restricts matching to Subscriptions in this namespace.
+	// Defaults to the StatusSubscription's own namespace.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Names is a set of glob patterns (as understood by path.Match)
+	// matched against Subscription names.
+	// +optional
+	Names []string `json:"names,omitempty"`
+
+	// LabelSelector matches Subscriptions by label, in addition to Names.
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+}
+
+// NotificationCallback describes where and how to deliver notifications.
+type NotificationCallback struct {
+	// URL is the HTTP(S) endpoint notifications are POSTed to.
+	URL string `json:"url"`
+
+	// SecretRef names a Secret in the StatusSubscription's namespace whose
+	// "secret" key is used to HMAC-SHA256-sign the notification body, carried
+	// in the X-Hub-Signature-256 header as "sha256=<hex>". Omit to send
+	// unsigned notifications.
+	// +optional
+	SecretRef string `json:"secretRef,omitempty"`
+}
+
+// StatusSubscriptionSpec defines which Subscriptions, which events, and
+// which callback a StatusSubscription wants notifications for.
+type StatusSubscriptionSpec struct {
+	Match StatusSubscriptionMatch `json:"match"`
+
+	// Events is the set of phase transitions to notify on. An empty list
+	// means all events.
+	// +optional
+	Events []StatusSubscriptionEvent `json:"events,omitempty"`
+
+	Callback NotificationCallback `json:"callback"`
+}
+
+// PendingNotification is one notification that still needs to be delivered
+// (or retried) to the callback. It is persisted on the StatusSubscription's
+// status so the worker pool survives a controller restart.
+type PendingNotification struct {
+	// ID is unique within the StatusSubscription, used to find this entry
+	// again when its retry state is updated or it is removed.
+	ID string `json:"id"`
+
+	Subject     string                  `json:"subject"`
+	Event       StatusSubscriptionEvent `json:"event"`
+	Payload     string                  `json:"payload"`
+	Attempts    int                     `json:"attempts"`
+	NextAttempt metav1.Time             `json:"nextAttempt,omitempty"`
+	LastError   string                  `json:"lastError,omitempty"`
+}
+
+// StatusSubscriptionStatus reports delivery progress for a
+// StatusSubscription.
+type StatusSubscriptionStatus struct {
+	// Pending holds notifications that are queued, in flight, or being
+	// retried with backoff.
+	// +optional
+	Pending []PendingNotification `json:"pending,omitempty"`
+
+	// DeliveredCount is the number of notifications successfully delivered
+	// over the lifetime of this StatusSubscription.
+	// +optional
+	DeliveredCount int64 `json:"deliveredCount,omitempty"`
+
+	// LastUpdateTime is the last time this status was written.
+	// +optional
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StatusSubscription lets an external system register interest in a set of
+// Subscription status transitions and have them delivered to an HTTP
+// callback with retries.
+type StatusSubscription struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   StatusSubscriptionSpec   `json:"spec"`
+	Status StatusSubscriptionStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StatusSubscriptionList contains a list of StatusSubscription.
+type StatusSubscriptionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StatusSubscription `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&StatusSubscription{}, &StatusSubscriptionList{})
+}
@@ -0,0 +1,214 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcmhub
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	dplv1alpha1 "github.com/IBM/multicloud-operators-deployable/pkg/apis/app/v1alpha1"
+	appv1alpha1 "github.com/IBM/multicloud-operators-subscription/pkg/apis/app/v1alpha1"
+)
+
+// annotationReconcileInterval lets a hub Subscription opt into periodic
+// drift checks at its own cadence, instead of the package default, using a
+// duration string as accepted by time.ParseDuration (e.g. "5m", "1h").
+const annotationReconcileInterval = "apps.open-cluster-management.io/reconcile-interval"
+
+const (
+	// driftDetectorTick is how often the watchdog wakes up to see which
+	// subscriptions are due for a drift check. Individual subscriptions are
+	// still only checked once their own interval has elapsed.
+	driftDetectorTick = 30 * time.Second
+
+	// defaultDriftInterval is used for subscriptions that do not set
+	// annotationReconcileInterval.
+	defaultDriftInterval = 10 * time.Minute
+)
+
+// driftDetector periodically verifies that the Deployables a hub
+// Subscription generated still match what its Placement and channel
+// currently resolve to, and requeues the Subscription for reconciliation
+// when they have drifted apart without a watch event ever firing (e.g. a
+// ClusterDecision bound to a PlacementRule changed without also touching
+// the Subscription or its Deployables).
+type driftDetector struct {
+	client.Client
+
+	// events is the channel the controller's source.Channel watch reads
+	// from, so a detected drift gets the Subscription requeued through the
+	// normal reconcile.Reconciler, rather than reconciling inline here.
+	events chan event.GenericEvent
+
+	mu          sync.Mutex
+	lastChecked map[types.NamespacedName]time.Time
+}
+
+// newDriftDetector builds a driftDetector backed by c. Its events channel
+// must be wired into the controller via a source.Channel watch in add().
+func newDriftDetector(c client.Client) *driftDetector {
+	return &driftDetector{
+		Client:      c,
+		events:      make(chan event.GenericEvent),
+		lastChecked: map[types.NamespacedName]time.Time{},
+	}
+}
+
+// blank assignment to verify that driftDetector implements manager.Runnable
+var _ manager.Runnable = &driftDetector{}
+
+// Start runs the watchdog loop until stop is closed, satisfying
+// manager.Runnable so it is started (and stopped) alongside the rest of the
+// manager's controllers.
+func (d *driftDetector) Start(stop <-chan struct{}) error {
+	ticker := time.NewTicker(driftDetectorTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			d.checkAll(stop)
+		}
+	}
+}
+
+func (d *driftDetector) checkAll(stop <-chan struct{}) {
+	subList := &appv1alpha1.SubscriptionList{}
+	if err := d.List(context.TODO(), subList, &client.ListOptions{}); err != nil {
+		klog.Error("drift detector failed to list subscriptions. error: ", err)
+		return
+	}
+
+	now := time.Now()
+	seen := make(map[types.NamespacedName]struct{}, len(subList.Items))
+
+	for i := range subList.Items {
+		sub := &subList.Items[i]
+		key := types.NamespacedName{Name: sub.GetName(), Namespace: sub.GetNamespace()}
+		seen[key] = struct{}{}
+
+		pl := sub.Spec.Placement
+		if pl == nil || (pl.PlacementRef == nil && pl.Clusters == nil && pl.ClusterSelector == nil) {
+			// not a hub subscription, nothing to propagate or drift-check
+			continue
+		}
+
+		if !d.due(key, sub.GetAnnotations(), now) {
+			continue
+		}
+
+		d.checkOne(sub, key, stop)
+	}
+
+	d.pruneLastChecked(seen)
+}
+
+// pruneLastChecked evicts lastChecked entries for subscriptions no longer
+// present, so the map doesn't grow without bound as subscriptions churn
+// over the manager's lifetime.
+func (d *driftDetector) pruneLastChecked(seen map[types.NamespacedName]struct{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key := range d.lastChecked {
+		if _, ok := seen[key]; !ok {
+			delete(d.lastChecked, key)
+		}
+	}
+}
+
+// due reports whether key's reconcile-interval has elapsed, and if so
+// records now as its new last-checked time.
+func (d *driftDetector) due(key types.NamespacedName, annotations map[string]string, now time.Time) bool {
+	interval := defaultDriftInterval
+
+	if raw, ok := annotations[annotationReconcileInterval]; ok && raw != "" {
+		if parsed, err := time.ParseDuration(raw); err == nil {
+			interval = parsed
+		} else {
+			klog.Info("Invalid ", annotationReconcileInterval, " annotation on ", key, ", using default. error: ", err)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if last, ok := d.lastChecked[key]; ok && now.Sub(last) < interval {
+		return false
+	}
+
+	d.lastChecked[key] = now
+
+	return true
+}
+
+// checkOne diffs the Deployables sub owns against the per-cluster statuses
+// it last recorded. The two are expected to stay in lockstep: propagation
+// creates one Deployable per resolved target, recorded under the same key
+// in sub.Status.Statuses. A mismatch means the Placement/channel resolution
+// moved without the Subscription (or its Deployables) ever being touched,
+// so no watch would have fired for it.
+func (d *driftDetector) checkOne(sub *appv1alpha1.Subscription, key types.NamespacedName, stop <-chan struct{}) {
+	dplList := &dplv1alpha1.DeployableList{}
+	if err := d.List(context.TODO(), dplList, &client.ListOptions{Namespace: sub.GetNamespace()}); err != nil {
+		klog.Error("drift detector failed to list deployables for subscription ", key, ", error: ", err)
+		return
+	}
+
+	owned := 0
+
+	for i := range dplList.Items {
+		for _, ref := range dplList.Items[i].GetOwnerReferences() {
+			if ref.Controller != nil && *ref.Controller && ref.UID == sub.GetUID() {
+				owned++
+				break
+			}
+		}
+	}
+
+	localKey := types.NamespacedName{}.String()
+	resolved := 0
+
+	for k := range sub.Status.Statuses {
+		if k != localKey {
+			resolved++
+		}
+	}
+
+	if owned == resolved {
+		return
+	}
+
+	klog.Info("drift detector found ", owned, " deployables but ", resolved,
+		" resolved targets for subscription ", key, ", requeuing")
+	driftDetectedTotal.Inc()
+
+	// Guard against the controller's source.Channel forwarder no longer
+	// draining this channel (e.g. mid manager shutdown): without the select
+	// on stop, this send would block forever and wedge the watchdog loop.
+	select {
+	case d.events <- event.GenericEvent{Meta: sub, Object: sub}:
+	case <-stop:
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcmhub
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func newTestDriftDetector() *driftDetector {
+	return &driftDetector{
+		events:      make(chan event.GenericEvent, 1),
+		lastChecked: map[types.NamespacedName]time.Time{},
+	}
+}
+
+func TestDriftDetectorDueFirstCheckAlwaysRuns(t *testing.T) {
+	d := newTestDriftDetector()
+	key := types.NamespacedName{Namespace: "ns1", Name: "sub1"}
+
+	if !d.due(key, nil, time.Now()) {
+		t.Fatal("due() = false on first check, want true")
+	}
+}
+
+func TestDriftDetectorDueRespectsDefaultInterval(t *testing.T) {
+	d := newTestDriftDetector()
+	key := types.NamespacedName{Namespace: "ns1", Name: "sub1"}
+	now := time.Now()
+
+	if !d.due(key, nil, now) {
+		t.Fatal("due() = false on first check, want true")
+	}
+
+	if d.due(key, nil, now.Add(time.Second)) {
+		t.Fatal("due() = true well before defaultDriftInterval has elapsed")
+	}
+
+	if !d.due(key, nil, now.Add(defaultDriftInterval+time.Second)) {
+		t.Fatal("due() = false after defaultDriftInterval has elapsed, want true")
+	}
+}
+
+func TestDriftDetectorDueHonorsAnnotationOverride(t *testing.T) {
+	d := newTestDriftDetector()
+	key := types.NamespacedName{Namespace: "ns1", Name: "sub1"}
+	now := time.Now()
+	annotations := map[string]string{annotationReconcileInterval: "1m"}
+
+	if !d.due(key, annotations, now) {
+		t.Fatal("due() = false on first check, want true")
+	}
+
+	if d.due(key, annotations, now.Add(30*time.Second)) {
+		t.Fatal("due() = true before the annotation's 1m interval elapsed")
+	}
+
+	if !d.due(key, annotations, now.Add(90*time.Second)) {
+		t.Fatal("due() = false after the annotation's 1m interval elapsed, want true")
+	}
+}
+
+func TestDriftDetectorDueIgnoresInvalidAnnotation(t *testing.T) {
+	d := newTestDriftDetector()
+	key := types.NamespacedName{Namespace: "ns1", Name: "sub1"}
+	annotations := map[string]string{annotationReconcileInterval: "not-a-duration"}
+
+	if !d.due(key, annotations, time.Now()) {
+		t.Fatal("due() = false on first check with an invalid annotation, want true (falls back to default)")
+	}
+}
+
+func TestDriftDetectorPruneLastCheckedEvictsUnseen(t *testing.T) {
+	d := newTestDriftDetector()
+	kept := types.NamespacedName{Namespace: "ns1", Name: "still-here"}
+	gone := types.NamespacedName{Namespace: "ns1", Name: "deleted"}
+
+	d.lastChecked[kept] = time.Now()
+	d.lastChecked[gone] = time.Now()
+
+	d.pruneLastChecked(map[types.NamespacedName]struct{}{kept: {}})
+
+	if _, ok := d.lastChecked[gone]; ok {
+		t.Error("pruneLastChecked() did not evict a subscription absent from the seen set")
+	}
+
+	if _, ok := d.lastChecked[kept]; !ok {
+		t.Error("pruneLastChecked() evicted a subscription still present in the seen set")
+	}
+}
@@ -35,6 +35,7 @@ import (
 
 	dplv1alpha1 "github.com/IBM/multicloud-operators-deployable/pkg/apis/app/v1alpha1"
 	appv1alpha1 "github.com/IBM/multicloud-operators-subscription/pkg/apis/app/v1alpha1"
+	"github.com/IBM/multicloud-operators-subscription/pkg/events"
 	"github.com/IBM/multicloud-operators-subscription/pkg/utils"
 )
 
@@ -43,20 +44,48 @@ import (
 * business logic.  Delete these comments after modifying this file.*
  */
 
+// hubEventSource identifies this hub as the CloudEvents "source" attribute
+// on every subscription lifecycle event it publishes.
+const hubEventSource = "multicloud-operators-subscription/mcmhub"
+
 // Add creates a new Subscription Controller and adds it to the Manager. The Manager will set fields on the Controller
 // and Start it when the Manager is Started.
 func Add(mgr manager.Manager) error {
-	return add(mgr, newReconciler(mgr))
+	rec := newReconciler(mgr)
+	detector := newDriftDetector(mgr.GetClient())
+
+	if err := add(mgr, rec, detector); err != nil {
+		return err
+	}
+
+	if err := mgr.Add(detector); err != nil {
+		return err
+	}
+
+	return AddStatusSubscription(mgr, rec.notifier)
 }
 
-// newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager) reconcile.Reconciler {
+// newReconciler returns a new ReconcileSubscription
+func newReconciler(mgr manager.Manager) *ReconcileSubscription {
 	erecorder, _ := utils.NewEventRecorder(mgr.GetConfig(), mgr.GetScheme())
 
+	defaultSink, err := events.DefaultSinkConfig()
+	if err != nil {
+		klog.Error("Invalid --events-sink configuration, disabling CloudEvents publishing. error: ", err)
+		defaultSink = events.SinkConfig{Kind: events.SinkKindNone}
+	}
+
+	eventPublisher, err := events.NewPublisher(defaultSink, hubEventSource)
+	if err != nil {
+		klog.Error("Failed to build CloudEvents publisher, events will not be published. error: ", err)
+	}
+
 	rec := &ReconcileSubscription{
-		Client:        mgr.GetClient(),
-		scheme:        mgr.GetScheme(),
-		eventRecorder: erecorder,
+		Client:         mgr.GetClient(),
+		scheme:         mgr.GetScheme(),
+		eventRecorder:  erecorder,
+		eventPublisher: eventPublisher,
+		notifier:       NewNotifier(mgr.GetClient()),
 	}
 
 	return rec
@@ -130,13 +159,20 @@ func (mapper *subscriptionMapper) Map(obj handler.MapObject) []reconcile.Request
 }
 
 // add adds a new Controller to mgr with r as the reconcile.Reconciler
-func add(mgr manager.Manager, r reconcile.Reconciler) error {
+func add(mgr manager.Manager, r reconcile.Reconciler, detector *driftDetector) error {
 	// Create a new controller
 	c, err := controller.New("mcmhub-subscription-controller", mgr, controller.Options{Reconciler: r})
 	if err != nil {
 		return err
 	}
 
+	// requeue subscriptions the drift detector finds out of sync with their
+	// Placement/channel resolution, even though no watch fired for them
+	err = c.Watch(&source.Channel{Source: detector.events}, &handler.EnqueueRequestForObject{})
+	if err != nil {
+		return err
+	}
+
 	// Watch for changes to primary resource Subscription
 	err = c.Watch(
 		&source.Kind{Type: &appv1alpha1.Subscription{}},
@@ -173,8 +209,11 @@ type ReconcileSubscription struct {
 	// This client, initialized using mgr.Client() above, is a split client
 	// that reads objects from the cache and writes to the apiserver
 	client.Client
-	scheme        *runtime.Scheme
-	eventRecorder *utils.EventRecorder
+	scheme              *runtime.Scheme
+	eventRecorder       *utils.EventRecorder
+	eventPublisher      *events.Publisher
+	eventPublisherCache events.PublisherCache
+	notifier            *Notifier
 }
 
 // Reconcile reads that state of the cluster for a Subscription object and makes changes based on the state read
@@ -182,6 +221,14 @@ type ReconcileSubscription struct {
 func (r *ReconcileSubscription) Reconcile(request reconcile.Request) (reconcile.Result, error) {
 	klog.Info("MCM Hub Reconciling subscription: ", request.NamespacedName)
 
+	start := time.Now()
+	phase := "unknown"
+
+	defer func() {
+		reconcileTotal.WithLabelValues(phase).Inc()
+		reconcileDuration.WithLabelValues(phase).Observe(time.Since(start).Seconds())
+	}()
+
 	instance := &appv1alpha1.Subscription{}
 	err := r.Get(context.TODO(), request.NamespacedName, instance)
 
@@ -190,9 +237,15 @@ func (r *ReconcileSubscription) Reconcile(request reconcile.Request) (reconcile.
 			klog.Info("Subscription: ", request.NamespacedName, " is gone")
 			// Object not found, delete existing subscriberitem if any
 
+			phase = "deleted"
+
+			r.notifier.FlushDeleted(context.TODO(), request.NamespacedName, nil)
+
 			return reconcile.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
+		phase = "get_error"
+
 		return reconcile.Result{}, err
 	}
 
@@ -247,7 +300,84 @@ func (r *ReconcileSubscription) Reconcile(request reconcile.Request) (reconcile.
 
 			result.RequeueAfter = 1 * time.Second
 		}
+
+		r.publishPhaseEvent(instance, orgst)
+		r.notifyStatusSubscribers(instance, orgst)
+	}
+
+	phase = string(instance.Status.Phase)
+	if phase == "" {
+		phase = "none"
 	}
 
 	return result, nil
 }
+
+// notifyStatusSubscribers enqueues a notification for every StatusSubscription
+// watching this Subscription's new phase, for delivery by the Notifier
+// worker pool.
+func (r *ReconcileSubscription) notifyStatusSubscribers(instance *appv1alpha1.Subscription, orgst *appv1alpha1.SubscriptionStatus) {
+	evt, ok := statusSubscriptionEventFor(instance.Status.Phase)
+	if !ok {
+		return
+	}
+
+	subject := types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+
+	r.notifier.Enqueue(context.TODO(), subject, instance.GetLabels(), evt, notificationPayload{
+		Subject:   subject.String(),
+		Event:     evt,
+		OldPhase:  string(orgst.Phase),
+		NewPhase:  string(instance.Status.Phase),
+		Reason:    instance.Status.Reason,
+		Statuses:  instance.Status.Statuses,
+		Timestamp: metav1.Now(),
+	})
+}
+
+func statusSubscriptionEventFor(phase appv1alpha1.SubscriptionPhase) (appv1alpha1.StatusSubscriptionEvent, bool) {
+	switch phase {
+	case appv1alpha1.SubscriptionPropagated:
+		return appv1alpha1.StatusSubscriptionPropagated, true
+	case appv1alpha1.SubscriptionFailed:
+		return appv1alpha1.StatusSubscriptionFailed, true
+	case appv1alpha1.SubscriptionSubscribed:
+		return appv1alpha1.StatusSubscriptionSubscribed, true
+	default:
+		return "", false
+	}
+}
+
+// publishPhaseEvent emits a CloudEvent for the subscription's new phase,
+// carrying the before/after Status as the event data. Publishing is best
+// effort: a sink outage should not fail the reconcile. A per-subscription
+// AnnotationEventSink override is resolved (and its Publisher cached) by
+// eventPublisherCache, so a Kafka/NATS override doesn't open a fresh
+// producer/connection on every reconcile.
+func (r *ReconcileSubscription) publishPhaseEvent(instance *appv1alpha1.Subscription, orgst *appv1alpha1.SubscriptionStatus) {
+	eventType := ""
+
+	switch instance.Status.Phase {
+	case appv1alpha1.SubscriptionPropagated:
+		eventType = events.TypePropagated
+	case appv1alpha1.SubscriptionFailed:
+		eventType = events.TypeFailed
+	case appv1alpha1.SubscriptionSubscribed:
+		eventType = events.TypeSubscribed
+	default:
+		return
+	}
+
+	publisher := r.eventPublisherCache.Get(instance.GetAnnotations(), hubEventSource, r.eventPublisher)
+
+	subject := types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}.String()
+
+	diff := struct {
+		Old *appv1alpha1.SubscriptionStatus `json:"old"`
+		New appv1alpha1.SubscriptionStatus  `json:"new"`
+	}{Old: orgst, New: instance.Status}
+
+	if err := publisher.PublishSubscriptionPhase(context.TODO(), eventType, subject, diff); err != nil {
+		klog.Error("Failed to publish CloudEvent for subscription ", subject, ", error: ", err)
+	}
+}
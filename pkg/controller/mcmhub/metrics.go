@@ -0,0 +1,41 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcmhub
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	reconcileTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "subscription_reconcile_total",
+		Help: "Total number of hub Subscription reconciles, by resulting phase.",
+	}, []string{"phase"})
+
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "subscription_reconcile_duration_seconds",
+		Help: "Duration of a hub Subscription reconcile.",
+	}, []string{"phase"})
+
+	driftDetectedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "subscription_drift_detected_total",
+		Help: "Total number of times the drift detector found the generated Deployable set diverged from the current Placement/channel resolution.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(reconcileTotal, reconcileDuration, driftDetectedTotal)
+}
@@ -0,0 +1,454 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcmhub
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	appv1alpha1 "github.com/IBM/multicloud-operators-subscription/pkg/apis/app/v1alpha1"
+)
+
+const (
+	notifierMaxAttempts        = 12
+	notifierBaseBackoff        = 5 * time.Second
+	notifierMaxBackoff         = 15 * time.Minute
+	notifierPerEndpointWorkers = 4
+)
+
+// notificationPayload is the JSON body POSTed to a StatusSubscription's
+// callback URL.
+type notificationPayload struct {
+	Subject   string                             `json:"subject"`
+	Event     appv1alpha1.StatusSubscriptionEvent `json:"event"`
+	OldPhase  string                              `json:"oldPhase,omitempty"`
+	NewPhase  string                              `json:"newPhase,omitempty"`
+	Reason    string                              `json:"reason,omitempty"`
+	Statuses  interface{}                         `json:"statuses,omitempty"`
+	Timestamp metav1.Time                         `json:"timestamp"`
+}
+
+// Notifier is a durable worker pool that delivers Subscription lifecycle
+// notifications to the callback URL declared on each StatusSubscription,
+// with exponential backoff and a concurrency limit per endpoint. Pending
+// notifications live on StatusSubscription.Status.Pending so a controller
+// restart only pauses delivery, it never drops an event.
+type Notifier struct {
+	client client.Client
+
+	mu         sync.Mutex
+	endpoints  map[string]chan struct{}            // callback URL -> semaphore
+	drainLocks map[types.NamespacedName]*sync.Mutex // StatusSubscription -> drain serialization lock
+}
+
+// NewNotifier builds a Notifier backed by c for reading StatusSubscriptions
+// and secrets, and for persisting delivery progress.
+func NewNotifier(c client.Client) *Notifier {
+	return &Notifier{
+		client:     c,
+		endpoints:  map[string]chan struct{}{},
+		drainLocks: map[types.NamespacedName]*sync.Mutex{},
+	}
+}
+
+// Enqueue appends a notification to every StatusSubscription whose Match
+// selects subject (a Subscription identified by name/namespace/labels) and
+// whose Events mask includes evt, then kicks off asynchronous delivery for
+// each.
+func (n *Notifier) Enqueue(ctx context.Context, subject types.NamespacedName, subjectLabels map[string]string, evt appv1alpha1.StatusSubscriptionEvent, payload notificationPayload) {
+	statusSubs, err := n.matchingStatusSubscriptions(ctx, subject, subjectLabels, evt)
+	if err != nil {
+		klog.Error("Failed to list StatusSubscriptions for ", subject, ", error: ", err)
+		return
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		klog.Error("Failed to marshal notification payload for ", subject, ", error: ", err)
+		return
+	}
+
+	for i := range statusSubs {
+		statusSub := &statusSubs[i]
+
+		notification := appv1alpha1.PendingNotification{
+			ID:      fmt.Sprintf("%s/%s/%d", subject.String(), evt, time.Now().UnixNano()),
+			Subject: subject.String(),
+			Event:   evt,
+			Payload: string(body),
+		}
+
+		if err := n.appendPending(ctx, statusSub, notification); err != nil {
+			klog.Error("Failed to persist pending notification on ", statusSub.GetName(), ", error: ", err)
+			continue
+		}
+
+		go n.drain(statusSub.GetNamespace(), statusSub.GetName())
+	}
+}
+
+// FlushDeleted is called when a Subscription is deleted: it enqueues a
+// terminal Deleted notification for subject, exactly as Enqueue does for any
+// other event, and delivery is attempted immediately. Notifications already
+// pending for subject are not rewritten or removed; they stay queued and are
+// delivered or retried as before.
+func (n *Notifier) FlushDeleted(ctx context.Context, subject types.NamespacedName, subjectLabels map[string]string) {
+	n.Enqueue(ctx, subject, subjectLabels, appv1alpha1.StatusSubscriptionDeleted, notificationPayload{
+		Subject:   subject.String(),
+		Event:     appv1alpha1.StatusSubscriptionDeleted,
+		Timestamp: metav1.Now(),
+	})
+}
+
+// Drain retries delivery of every due pending notification on statusSub,
+// and reports how long until the earliest remaining one comes due (zero if
+// none are left). It is exported so the StatusSubscription controller can
+// call it on every reconcile -- that's what resumes delivery after a
+// controller restart -- and can requeue itself for the returned duration so
+// a backed-off notification is retried on schedule even with no further
+// watch events.
+func (n *Notifier) Drain(namespace, name string) time.Duration {
+	return n.drain(namespace, name)
+}
+
+func (n *Notifier) drain(namespace, name string) time.Duration {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+
+	// Enqueue's own goroutine and the StatusSubscription controller's watch
+	// (fired by the very Status().Update calls drain's delivery path makes)
+	// can both call drain for the same key concurrently. Without
+	// serializing here, both could read the same pending notification
+	// before either writes back, double-delivering it and racing on the
+	// attempt/backoff bookkeeping.
+	drainMu := n.drainMuFor(key)
+	drainMu.Lock()
+	defer drainMu.Unlock()
+
+	statusSub := &appv1alpha1.StatusSubscription{}
+	if err := n.client.Get(context.TODO(), key, statusSub); err != nil {
+		klog.V(2).Info("Skipping drain for deleted StatusSubscription ", key)
+		return 0
+	}
+
+	if len(statusSub.Status.Pending) == 0 {
+		return 0
+	}
+
+	sem := n.semaphoreFor(statusSub.Spec.Callback.URL)
+	attempted := false
+
+	for _, notification := range statusSub.Status.Pending {
+		if notification.NextAttempt.After(time.Now()) {
+			continue
+		}
+
+		sem <- struct{}{}
+		n.deliver(key, statusSub.Spec.Callback, notification)
+		<-sem
+		attempted = true
+	}
+
+	if attempted {
+		// deliver() persisted its own Get/Update of statusSub, so re-read it
+		// to see the post-delivery Pending queue before computing the next
+		// requeue time.
+		if err := n.client.Get(context.TODO(), key, statusSub); err != nil {
+			klog.V(2).Info("Skipping requeue calculation for deleted StatusSubscription ", key)
+			return 0
+		}
+	}
+
+	return earliestNextAttempt(statusSub.Status.Pending)
+}
+
+// earliestNextAttempt returns how long until the soonest NextAttempt among
+// pending, or zero if pending is empty. If the soonest one is already due,
+// it returns one second.
+func earliestNextAttempt(pending []appv1alpha1.PendingNotification) time.Duration {
+	var earliest *time.Time
+
+	for i := range pending {
+		next := pending[i].NextAttempt.Time
+		if earliest == nil || next.Before(*earliest) {
+			earliest = &next
+		}
+	}
+
+	if earliest == nil {
+		return 0
+	}
+
+	if d := time.Until(*earliest); d > 0 {
+		return d
+	}
+
+	return time.Second
+}
+
+// drainMuFor returns the per-StatusSubscription mutex that serializes drain
+// calls for key, creating it on first use.
+func (n *Notifier) drainMuFor(key types.NamespacedName) *sync.Mutex {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	mu, ok := n.drainLocks[key]
+	if !ok {
+		mu = &sync.Mutex{}
+		n.drainLocks[key] = mu
+	}
+
+	return mu
+}
+
+func (n *Notifier) semaphoreFor(url string) chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	sem, ok := n.endpoints[url]
+	if !ok {
+		sem = make(chan struct{}, notifierPerEndpointWorkers)
+		n.endpoints[url] = sem
+	}
+
+	return sem
+}
+
+func (n *Notifier) deliver(key types.NamespacedName, callback appv1alpha1.NotificationCallback, notification appv1alpha1.PendingNotification) {
+	err := n.send(key.Namespace, callback, notification)
+
+	ctx := context.TODO()
+
+	if err == nil {
+		n.removePending(ctx, key, notification.ID, true)
+		return
+	}
+
+	klog.Info("Failed to deliver notification ", notification.ID, " to ", callback.URL, ", error: ", err)
+
+	notification.Attempts++
+	notification.LastError = err.Error()
+
+	if notification.Attempts >= notifierMaxAttempts {
+		klog.Error("Giving up on notification ", notification.ID, " after ", notification.Attempts, " attempts")
+		n.removePending(ctx, key, notification.ID, false)
+
+		return
+	}
+
+	notification.NextAttempt = metav1.NewTime(time.Now().Add(backoffFor(notification.Attempts)))
+	n.updatePending(ctx, key, notification)
+}
+
+func backoffFor(attempts int) time.Duration {
+	d := notifierBaseBackoff << uint(attempts)
+	if d > notifierMaxBackoff || d <= 0 {
+		return notifierMaxBackoff
+	}
+
+	return d
+}
+
+func (n *Notifier) send(namespace string, callback appv1alpha1.NotificationCallback, notification appv1alpha1.PendingNotification) error {
+	body := []byte(notification.Payload)
+
+	req, err := http.NewRequest(http.MethodPost, callback.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	if callback.SecretRef != "" {
+		secret, err := n.loadSecret(namespace, callback.SecretRef)
+		if err != nil {
+			return err
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (n *Notifier) loadSecret(namespace, name string) ([]byte, error) {
+	secobj := &corev1.Secret{}
+	if err := n.client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, secobj); err != nil {
+		return nil, err
+	}
+
+	return secobj.Data["secret"], nil
+}
+
+func (n *Notifier) matchingStatusSubscriptions(ctx context.Context, subject types.NamespacedName, subjectLabels map[string]string, evt appv1alpha1.StatusSubscriptionEvent) ([]appv1alpha1.StatusSubscription, error) {
+	list := &appv1alpha1.StatusSubscriptionList{}
+	if err := n.client.List(ctx, list, &client.ListOptions{}); err != nil {
+		return nil, err
+	}
+
+	matched := make([]appv1alpha1.StatusSubscription, 0, len(list.Items))
+
+	for _, statusSub := range list.Items {
+		if !matchesEvent(statusSub.Spec.Events, evt) {
+			continue
+		}
+
+		match := statusSub.Spec.Match
+		if match.Namespace == "" {
+			match.Namespace = statusSub.GetNamespace()
+		}
+
+		if !matchesSubject(match, subject, subjectLabels) {
+			continue
+		}
+
+		matched = append(matched, statusSub)
+	}
+
+	return matched, nil
+}
+
+// matchesSubject reports whether match selects the Subscription identified
+// by subject/subjectLabels.
+func matchesSubject(match appv1alpha1.StatusSubscriptionMatch, subject types.NamespacedName, subjectLabels map[string]string) bool {
+	if match.Namespace != "" && match.Namespace != subject.Namespace {
+		return false
+	}
+
+	nameOK := len(match.Names) == 0
+
+	for _, pattern := range match.Names {
+		if ok, _ := path.Match(pattern, subject.Name); ok {
+			nameOK = true
+			break
+		}
+	}
+
+	if !nameOK {
+		return false
+	}
+
+	if match.LabelSelector == nil {
+		return true
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(match.LabelSelector)
+	if err != nil {
+		klog.Error("Invalid labelSelector on StatusSubscription match, error: ", err)
+		return false
+	}
+
+	return selector.Matches(labels.Set(subjectLabels))
+}
+
+// appendPending adds notification to statusSub's persisted pending queue.
+func (n *Notifier) appendPending(ctx context.Context, statusSub *appv1alpha1.StatusSubscription, notification appv1alpha1.PendingNotification) error {
+	statusSub.Status.Pending = append(statusSub.Status.Pending, notification)
+	statusSub.Status.LastUpdateTime = metav1.Now()
+
+	return n.client.Status().Update(ctx, statusSub)
+}
+
+// updatePending replaces the persisted copy of a pending notification
+// (e.g. after a failed delivery bumped its attempt count and backoff).
+func (n *Notifier) updatePending(ctx context.Context, key types.NamespacedName, notification appv1alpha1.PendingNotification) {
+	statusSub := &appv1alpha1.StatusSubscription{}
+	if err := n.client.Get(ctx, key, statusSub); err != nil {
+		klog.Error("Failed to reload StatusSubscription ", key, " to update notification, error: ", err)
+		return
+	}
+
+	for i := range statusSub.Status.Pending {
+		if statusSub.Status.Pending[i].ID == notification.ID {
+			statusSub.Status.Pending[i] = notification
+		}
+	}
+
+	statusSub.Status.LastUpdateTime = metav1.Now()
+
+	if err := n.client.Status().Update(ctx, statusSub); err != nil {
+		klog.Error("Failed to persist retry state for notification ", notification.ID, ", error: ", err)
+	}
+}
+
+// removePending drops a delivered (or permanently failed) notification from
+// the persisted queue, bumping DeliveredCount on success.
+func (n *Notifier) removePending(ctx context.Context, key types.NamespacedName, id string, delivered bool) {
+	statusSub := &appv1alpha1.StatusSubscription{}
+	if err := n.client.Get(ctx, key, statusSub); err != nil {
+		klog.Error("Failed to reload StatusSubscription ", key, " to clear notification, error: ", err)
+		return
+	}
+
+	pending := statusSub.Status.Pending[:0]
+
+	for _, p := range statusSub.Status.Pending {
+		if p.ID != id {
+			pending = append(pending, p)
+		}
+	}
+
+	statusSub.Status.Pending = pending
+	if delivered {
+		statusSub.Status.DeliveredCount++
+	}
+
+	statusSub.Status.LastUpdateTime = metav1.Now()
+
+	if err := n.client.Status().Update(ctx, statusSub); err != nil {
+		klog.Error("Failed to persist delivery result for notification ", id, ", error: ", err)
+	}
+}
+
+func matchesEvent(events []appv1alpha1.StatusSubscriptionEvent, evt appv1alpha1.StatusSubscriptionEvent) bool {
+	if len(events) == 0 {
+		return true
+	}
+
+	for _, e := range events {
+		if e == evt {
+			return true
+		}
+	}
+
+	return false
+}
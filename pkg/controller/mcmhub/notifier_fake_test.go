@@ -0,0 +1,152 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcmhub
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	appv1alpha1 "github.com/IBM/multicloud-operators-subscription/pkg/apis/app/v1alpha1"
+)
+
+func newTestNotifier(objs ...runtime.Object) *Notifier {
+	scheme := runtime.NewScheme()
+	_ = appv1alpha1.SchemeBuilder.AddToScheme(scheme)
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objs...).Build()
+
+	return NewNotifier(c)
+}
+
+func TestNotifierEnqueueAppendsPendingAndDrainDelivers(t *testing.T) {
+	delivered := make(chan struct{}, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delivered <- struct{}{}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	statusSub := &appv1alpha1.StatusSubscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "watch-everything", Namespace: "ns1"},
+		Spec: appv1alpha1.StatusSubscriptionSpec{
+			Match:    appv1alpha1.StatusSubscriptionMatch{Names: []string{"*"}},
+			Callback: appv1alpha1.NotificationCallback{URL: server.URL},
+		},
+	}
+
+	n := newTestNotifier(statusSub)
+
+	subject := types.NamespacedName{Namespace: "ns1", Name: "app-sub"}
+	n.Enqueue(context.TODO(), subject, nil, appv1alpha1.StatusSubscriptionPropagated, notificationPayload{
+		Subject: subject.String(),
+		Event:   appv1alpha1.StatusSubscriptionPropagated,
+	})
+
+	select {
+	case <-delivered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Enqueue() did not trigger delivery to the matching StatusSubscription's callback")
+	}
+
+	var got appv1alpha1.StatusSubscription
+
+	for i := 0; i < 20; i++ {
+		if err := n.client.Get(context.TODO(), types.NamespacedName{Namespace: "ns1", Name: "watch-everything"}, &got); err != nil {
+			t.Fatalf("failed to reload StatusSubscription: %v", err)
+		}
+
+		if len(got.Status.Pending) == 0 {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if len(got.Status.Pending) != 0 {
+		t.Errorf("Status.Pending = %d entries after successful delivery, want 0", len(got.Status.Pending))
+	}
+
+	if got.Status.DeliveredCount != 1 {
+		t.Errorf("Status.DeliveredCount = %d, want 1", got.Status.DeliveredCount)
+	}
+}
+
+func TestNotifierDrainSkipsDeletedStatusSubscription(t *testing.T) {
+	n := newTestNotifier()
+
+	if got := n.drain("ns1", "does-not-exist"); got != 0 {
+		t.Errorf("drain() on a missing StatusSubscription = %v, want 0", got)
+	}
+}
+
+func TestNotifierFlushDeletedEnqueuesDeletedEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	statusSub := &appv1alpha1.StatusSubscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "watch-deletes", Namespace: "ns1"},
+		Spec: appv1alpha1.StatusSubscriptionSpec{
+			Match:    appv1alpha1.StatusSubscriptionMatch{Names: []string{"*"}},
+			Events:   []appv1alpha1.StatusSubscriptionEvent{appv1alpha1.StatusSubscriptionDeleted},
+			Callback: appv1alpha1.NotificationCallback{URL: server.URL},
+		},
+	}
+
+	n := newTestNotifier(statusSub)
+
+	subject := types.NamespacedName{Namespace: "ns1", Name: "app-sub"}
+	n.FlushDeleted(context.TODO(), subject, nil)
+
+	var got appv1alpha1.StatusSubscription
+
+	for i := 0; i < 20; i++ {
+		if err := n.client.Get(context.TODO(), types.NamespacedName{Namespace: "ns1", Name: "watch-deletes"}, &got); err != nil {
+			t.Fatalf("failed to reload StatusSubscription: %v", err)
+		}
+
+		if got.Status.DeliveredCount > 0 {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if got.Status.DeliveredCount != 1 {
+		t.Errorf("Status.DeliveredCount = %d, want 1 after FlushDeleted's notification was delivered", got.Status.DeliveredCount)
+	}
+}
+
+func TestNotifierDrainMuForReturnsSameLockForSameKey(t *testing.T) {
+	n := newTestNotifier()
+	key := types.NamespacedName{Namespace: "ns1", Name: "sub1"}
+
+	first := n.drainMuFor(key)
+	second := n.drainMuFor(key)
+
+	if first != second {
+		t.Error("drainMuFor() returned different mutexes for the same StatusSubscription key")
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcmhub
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appv1alpha1 "github.com/IBM/multicloud-operators-subscription/pkg/apis/app/v1alpha1"
+)
+
+func TestBackoffForGrowsExponentiallyAndCaps(t *testing.T) {
+	if got := backoffFor(0); got != notifierBaseBackoff {
+		t.Errorf("backoffFor(0) = %v, want %v", got, notifierBaseBackoff)
+	}
+
+	if got := backoffFor(1); got != 2*notifierBaseBackoff {
+		t.Errorf("backoffFor(1) = %v, want %v", got, 2*notifierBaseBackoff)
+	}
+
+	if got := backoffFor(notifierMaxAttempts + 10); got != notifierMaxBackoff {
+		t.Errorf("backoffFor(%d) = %v, want it capped at %v", notifierMaxAttempts+10, got, notifierMaxBackoff)
+	}
+}
+
+func TestEarliestNextAttemptEmpty(t *testing.T) {
+	if got := earliestNextAttempt(nil); got != 0 {
+		t.Errorf("earliestNextAttempt(nil) = %v, want 0", got)
+	}
+}
+
+func TestEarliestNextAttemptPicksSoonest(t *testing.T) {
+	now := time.Now()
+
+	pending := []appv1alpha1.PendingNotification{
+		{NextAttempt: metav1.NewTime(now.Add(10 * time.Minute))},
+		{NextAttempt: metav1.NewTime(now.Add(2 * time.Minute))},
+		{NextAttempt: metav1.NewTime(now.Add(5 * time.Minute))},
+	}
+
+	got := earliestNextAttempt(pending)
+
+	if got <= 0 || got > 2*time.Minute {
+		t.Errorf("earliestNextAttempt() = %v, want roughly 2m (the soonest entry)", got)
+	}
+}
+
+func TestEarliestNextAttemptPastDueStillRequeuesSoon(t *testing.T) {
+	pending := []appv1alpha1.PendingNotification{
+		{NextAttempt: metav1.NewTime(time.Now().Add(-time.Minute))},
+	}
+
+	got := earliestNextAttempt(pending)
+
+	if got <= 0 {
+		t.Errorf("earliestNextAttempt() = %v, want a positive requeue delay even for a past-due entry", got)
+	}
+}
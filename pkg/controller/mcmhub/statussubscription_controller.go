@@ -0,0 +1,90 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mcmhub
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	appv1alpha1 "github.com/IBM/multicloud-operators-subscription/pkg/apis/app/v1alpha1"
+)
+
+// AddStatusSubscription creates a new StatusSubscription Controller and adds
+// it to the Manager, sharing notifier with the Subscription reconciler so
+// enqueued notifications and restart-time redelivery use the same worker
+// pool.
+func AddStatusSubscription(mgr manager.Manager, notifier *Notifier) error {
+	r := &ReconcileStatusSubscription{
+		Client:   mgr.GetClient(),
+		scheme:   mgr.GetScheme(),
+		notifier: notifier,
+	}
+
+	c, err := controller.New("mcmhub-statussubscription-controller", mgr, controller.Options{Reconciler: r})
+	if err != nil {
+		return err
+	}
+
+	return c.Watch(&source.Kind{Type: &appv1alpha1.StatusSubscription{}}, &handler.EnqueueRequestForObject{})
+}
+
+// blank assignment to verify that ReconcileStatusSubscription implements reconcile.Reconciler
+var _ reconcile.Reconciler = &ReconcileStatusSubscription{}
+
+// ReconcileStatusSubscription reconciles a StatusSubscription object. Its
+// job is purely to resume delivery of whatever is already persisted in
+// Status.Pending -- new notifications are enqueued directly by
+// ReconcileSubscription as Subscriptions transition phase.
+type ReconcileStatusSubscription struct {
+	client.Client
+	scheme   *runtime.Scheme
+	notifier *Notifier
+}
+
+// Reconcile drains any pending notifications for the StatusSubscription,
+// then requeues itself for whenever the earliest remaining one is next due.
+// This is what makes delivery resume after a controller restart and keeps a
+// backed-off notification retrying on schedule: the worker pool itself is
+// in-memory only, but the queue it drains from is persisted on
+// Status.Pending.
+func (r *ReconcileStatusSubscription) Reconcile(request reconcile.Request) (reconcile.Result, error) {
+	klog.V(2).Info("Reconciling StatusSubscription: ", request.NamespacedName)
+
+	instance := &appv1alpha1.StatusSubscription{}
+
+	err := r.Get(context.TODO(), request.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+
+		return reconcile.Result{}, err
+	}
+
+	if requeueAfter := r.notifier.Drain(request.Namespace, request.Name); requeueAfter > 0 {
+		return reconcile.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	return reconcile.Result{}, nil
+}
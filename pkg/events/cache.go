@@ -0,0 +1,74 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"sync"
+
+	"k8s.io/klog"
+)
+
+// PublisherCache memoizes the Publishers built for per-object
+// AnnotationEventSink overrides, keyed by the raw sink string. Without
+// this, resolving an override on every reconcile/webhook call would build
+// a brand-new CloudEvents client (a fresh Kafka producer or NATS
+// connection) each time, leaking sockets and goroutines. The zero value is
+// ready to use.
+type PublisherCache struct {
+	mu    sync.Mutex
+	byKey map[string]*Publisher
+}
+
+// Get returns the Publisher for annotations' AnnotationEventSink override,
+// building and caching it on first use. It returns fallback unchanged when
+// the annotation is absent (the common case, since most objects rely on
+// the process-wide default sink) or when the override fails to parse or
+// build, so a bad per-object annotation never breaks event emission for
+// everyone else.
+func (c *PublisherCache) Get(annotations map[string]string, source string, fallback *Publisher) *Publisher {
+	raw, ok := annotations[AnnotationEventSink]
+	if !ok || raw == "" {
+		return fallback
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.byKey == nil {
+		c.byKey = map[string]*Publisher{}
+	}
+
+	key := source + "|" + raw
+
+	if publisher, ok := c.byKey[key]; ok {
+		return publisher
+	}
+
+	sink, err := ParseSinkConfig(raw)
+	if err != nil {
+		klog.Error("Invalid ", AnnotationEventSink, " annotation ", raw, ", falling back to the default sink. error: ", err)
+		return fallback
+	}
+
+	publisher, err := NewPublisher(sink, source)
+	if err != nil {
+		klog.Error("Failed to build CloudEvents publisher override for sink ", raw, ", falling back to the default sink. error: ", err)
+		return fallback
+	}
+
+	c.byKey[key] = publisher
+
+	return publisher
+}
@@ -0,0 +1,73 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import "testing"
+
+func TestPublisherCacheGetFallsBackWithNoAnnotation(t *testing.T) {
+	var cache PublisherCache
+
+	fallback := &Publisher{source: "hub"}
+
+	if got := cache.Get(nil, "hub", fallback); got != fallback {
+		t.Errorf("Get() = %p, want the fallback publisher %p", got, fallback)
+	}
+
+	if got := cache.Get(map[string]string{"other": "annotation"}, "hub", fallback); got != fallback {
+		t.Errorf("Get() = %p, want the fallback publisher when the sink annotation is absent", got)
+	}
+}
+
+func TestPublisherCacheGetFallsBackOnInvalidAnnotation(t *testing.T) {
+	var cache PublisherCache
+
+	fallback := &Publisher{source: "hub"}
+	annotations := map[string]string{AnnotationEventSink: "not-a-sink"}
+
+	if got := cache.Get(annotations, "hub", fallback); got != fallback {
+		t.Errorf("Get() = %p, want the fallback publisher when the override fails to parse", got)
+	}
+}
+
+func TestPublisherCacheGetBuildsAndReusesOverride(t *testing.T) {
+	var cache PublisherCache
+
+	fallback := &Publisher{source: "hub"}
+	annotations := map[string]string{AnnotationEventSink: "http:https://override.example.com"}
+
+	first := cache.Get(annotations, "hub", fallback)
+	if first == fallback {
+		t.Fatal("Get() returned the fallback publisher, want a built override")
+	}
+
+	second := cache.Get(annotations, "hub", fallback)
+	if second != first {
+		t.Error("Get() built a second publisher for the same annotation instead of reusing the cached one")
+	}
+}
+
+func TestPublisherCacheGetKeysBySource(t *testing.T) {
+	var cache PublisherCache
+
+	fallback := &Publisher{source: "hub"}
+	annotations := map[string]string{AnnotationEventSink: "http:https://override.example.com"}
+
+	forHub := cache.Get(annotations, "hub", fallback)
+	forOther := cache.Get(annotations, "other-source", fallback)
+
+	if forHub == forOther {
+		t.Error("Get() reused a publisher across different sources, want one cache entry per source")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import "flag"
+
+// AnnotationEventSink lets a single Subscription override the process-wide
+// --events-sink flag with its own sink, using the same "<kind>:<target>"
+// syntax.
+const AnnotationEventSink = "apps.open-cluster-management.io/event-sink"
+
+var defaultSink string
+
+// RegisterFlags wires the --events-sink flag into fs. Call this from
+// main() alongside the manager's other flags.
+func RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&defaultSink, "events-sink", "",
+		"CloudEvents sink for subscription lifecycle and webhook events, e.g. http:https://host/ingest, "+
+			"kafka:broker:9092?topic=t, or nats:nats://host:4222?subject=s. Unset disables event emission.")
+}
+
+// DefaultSinkConfig parses the process-wide --events-sink flag.
+func DefaultSinkConfig() (SinkConfig, error) {
+	return ParseSinkConfig(defaultSink)
+}
+
+// SinkConfigFor resolves the sink for a single subscription: its
+// AnnotationEventSink annotation if present, otherwise the process default.
+func SinkConfigFor(annotations map[string]string) (SinkConfig, error) {
+	if sink, ok := annotations[AnnotationEventSink]; ok && sink != "" {
+		return ParseSinkConfig(sink)
+	}
+
+	return DefaultSinkConfig()
+}
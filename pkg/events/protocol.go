@@ -0,0 +1,57 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"fmt"
+	"strings"
+
+	cloudeventshttp "github.com/cloudevents/sdk-go/protocol/kafka_sarama/v2"
+	cloudeventsnats "github.com/cloudevents/sdk-go/protocol/nats/v2"
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// newProtocol builds the cloudevents transport binding for cfg.Kind.
+func newProtocol(cfg SinkConfig) (interface{}, error) {
+	switch cfg.Kind {
+	case SinkKindHTTP:
+		opts := []cloudevents.Option{cloudevents.WithTarget(cfg.Target)}
+		if cfg.Binary {
+			opts = append(opts, cloudevents.WithEncoding(cloudevents.EncodingBinary))
+		} else {
+			opts = append(opts, cloudevents.WithEncoding(cloudevents.EncodingStructured))
+		}
+
+		return cloudevents.NewHTTP(opts...)
+	case SinkKindKafka:
+		topic := cfg.Options["topic"]
+		if topic == "" {
+			return nil, fmt.Errorf("kafka sink requires a topic= option")
+		}
+
+		brokers := strings.Split(cfg.Target, ",")
+
+		return cloudeventshttp.NewSender(brokers, topic, nil)
+	case SinkKindNATS:
+		subject := cfg.Options["subject"]
+		if subject == "" {
+			return nil, fmt.Errorf("nats sink requires a subject= option")
+		}
+
+		return cloudeventsnats.NewSender(cfg.Target, subject, cloudeventsnats.NatsOptions())
+	default:
+		return nil, fmt.Errorf("unsupported sink kind %q", cfg.Kind)
+	}
+}
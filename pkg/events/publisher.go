@@ -0,0 +1,100 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"fmt"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"k8s.io/klog"
+)
+
+// Event types emitted for subscription phase transitions and webhook
+// triggers. They are suffixed onto "com.ibm.multicloud.subscription.".
+const (
+	TypePropagated     = "com.ibm.multicloud.subscription.propagated"
+	TypeFailed         = "com.ibm.multicloud.subscription.failed"
+	TypeSubscribed     = "com.ibm.multicloud.subscription.subscribed"
+	TypeWebhookTrigger = "com.ibm.multicloud.subscription.push"
+)
+
+// Publisher emits CloudEvents to the sink a subscription (or the process
+// default) is configured with. A Publisher with no sink configured is a
+// no-op, so wiring it into the reconcile loop is always safe.
+type Publisher struct {
+	source string
+	client cloudevents.Client
+}
+
+// NewPublisher builds a Publisher that sends structured-mode (or
+// binary-mode, for HTTP) CloudEvents to the given sink. source identifies
+// the hub/cluster emitting the events, and becomes the CloudEvents
+// "source" attribute on every event this Publisher sends.
+func NewPublisher(cfg SinkConfig, source string) (*Publisher, error) {
+	if cfg.Kind == SinkKindNone || cfg.Kind == "" {
+		return &Publisher{source: source}, nil
+	}
+
+	protocol, err := newProtocol(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s events sink: %w", cfg.Kind, err)
+	}
+
+	client, err := cloudevents.NewClient(protocol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build events client: %w", err)
+	}
+
+	return &Publisher{source: source, client: client}, nil
+}
+
+// PublishSubscriptionPhase emits a CloudEvent recording a Subscription
+// phase transition. statusDiff is the free-form representation of what
+// changed (typically old vs. new Status), carried as the event data.
+func (p *Publisher) PublishSubscriptionPhase(ctx context.Context, eventType string, subject string, statusDiff interface{}) error {
+	return p.publish(ctx, eventType, subject, statusDiff)
+}
+
+// PublishWebhookTrigger emits a CloudEvent recording an accepted webhook
+// push that triggered a subscription reconcile.
+func (p *Publisher) PublishWebhookTrigger(ctx context.Context, subject string, repoEvent interface{}) error {
+	return p.publish(ctx, TypeWebhookTrigger, subject, repoEvent)
+}
+
+func (p *Publisher) publish(ctx context.Context, eventType, subject string, data interface{}) error {
+	if p == nil || p.client == nil {
+		// No sink configured: emitting CloudEvents is opt-in.
+		return nil
+	}
+
+	event := cloudevents.NewEvent()
+	event.SetSpecVersion(cloudevents.VersionV1)
+	event.SetType(eventType)
+	event.SetSource(p.source)
+	event.SetSubject(subject)
+
+	if err := event.SetData(cloudevents.ApplicationJSON, data); err != nil {
+		return fmt.Errorf("failed to set event data: %w", err)
+	}
+
+	result := p.client.Send(ctx, event)
+	if cloudevents.IsUndelivered(result) {
+		klog.Error("Failed to deliver CloudEvent ", eventType, " for ", subject, ", error: ", result)
+		return result
+	}
+
+	return nil
+}
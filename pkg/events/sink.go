@@ -0,0 +1,117 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package events publishes CloudEvents v1.0 notifications for subscription
+// lifecycle transitions and webhook triggers, so operators can integrate
+// with systems that want an auditable event stream instead of (or in
+// addition to) Kubernetes Events.
+package events
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SinkKind identifies the transport a SinkConfig talks to.
+type SinkKind string
+
+const (
+	SinkKindHTTP  SinkKind = "http"
+	SinkKindKafka SinkKind = "kafka"
+	SinkKindNATS  SinkKind = "nats"
+	// SinkKindNone disables event emission. It is the default so that
+	// clusters that have not opted in see no behavior change.
+	SinkKindNone SinkKind = "none"
+)
+
+// SinkConfig describes where CloudEvents should be delivered. It is built
+// either from the process-wide --events-sink flag or from a subscription's
+// apps.open-cluster-management.io/event-sink annotation, both of which use
+// the same "<kind>:<target>[?option=value&...]" syntax, for example:
+//
+//	http:https://events.example.com/ingest
+//	http-binary:https://events.example.com/ingest
+//	kafka:broker1:9092,broker2:9092?topic=subscription-events
+//	nats:nats://nats.example.com:4222?subject=subscription-events
+type SinkConfig struct {
+	Kind SinkKind
+	// Target is the sink-specific address: a URL for HTTP, a
+	// comma-separated broker list for Kafka, or a server URL for NATS.
+	Target string
+	// Binary selects CloudEvents binary content mode for HTTP sinks.
+	// Structured mode (the default) is used otherwise.
+	Binary bool
+	// Options carries sink-specific settings parsed from the query string,
+	// e.g. "topic" for Kafka or "subject" for NATS.
+	Options map[string]string
+}
+
+// ParseSinkConfig parses the "<kind>:<target>[?opt=val&...]" syntax shared by
+// the --events-sink flag and the per-subscription event-sink annotation.
+func ParseSinkConfig(raw string) (SinkConfig, error) {
+	if raw == "" {
+		return SinkConfig{Kind: SinkKindNone}, nil
+	}
+
+	kindAndRest := strings.SplitN(raw, ":", 2)
+	if len(kindAndRest) != 2 {
+		return SinkConfig{}, fmt.Errorf("invalid sink configuration %q, want <kind>:<target>", raw)
+	}
+
+	kind := SinkKind(kindAndRest[0])
+	target := kindAndRest[1]
+	binary := false
+
+	if kind == "http-binary" {
+		kind = SinkKindHTTP
+		binary = true
+	}
+
+	target, options, err := splitTargetOptions(target)
+	if err != nil {
+		return SinkConfig{}, err
+	}
+
+	switch kind {
+	case SinkKindHTTP, SinkKindKafka, SinkKindNATS:
+	default:
+		return SinkConfig{}, fmt.Errorf("unsupported sink kind %q", kind)
+	}
+
+	return SinkConfig{Kind: kind, Target: target, Binary: binary, Options: options}, nil
+}
+
+func splitTargetOptions(target string) (string, map[string]string, error) {
+	parts := strings.SplitN(target, "?", 2)
+	if len(parts) == 1 {
+		return parts[0], nil, nil
+	}
+
+	options := map[string]string{}
+
+	for _, kv := range strings.Split(parts[1], "&") {
+		if kv == "" {
+			continue
+		}
+
+		pair := strings.SplitN(kv, "=", 2)
+		if len(pair) != 2 {
+			return "", nil, fmt.Errorf("invalid sink option %q", kv)
+		}
+
+		options[pair[0]] = pair[1]
+	}
+
+	return parts[0], options, nil
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import "testing"
+
+func TestParseSinkConfigEmptyIsNone(t *testing.T) {
+	cfg, err := ParseSinkConfig("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Kind != SinkKindNone {
+		t.Errorf("Kind = %q, want %q", cfg.Kind, SinkKindNone)
+	}
+}
+
+func TestParseSinkConfigHTTP(t *testing.T) {
+	cfg, err := ParseSinkConfig("http:https://events.example.com/ingest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Kind != SinkKindHTTP || cfg.Target != "https://events.example.com/ingest" || cfg.Binary {
+		t.Errorf("unexpected SinkConfig: %+v", cfg)
+	}
+}
+
+func TestParseSinkConfigHTTPBinary(t *testing.T) {
+	cfg, err := ParseSinkConfig("http-binary:https://events.example.com/ingest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Kind != SinkKindHTTP || !cfg.Binary {
+		t.Errorf("unexpected SinkConfig: %+v", cfg)
+	}
+}
+
+func TestParseSinkConfigKafkaWithOptions(t *testing.T) {
+	cfg, err := ParseSinkConfig("kafka:broker1:9092,broker2:9092?topic=subscription-events")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Kind != SinkKindKafka || cfg.Target != "broker1:9092,broker2:9092" {
+		t.Errorf("unexpected SinkConfig: %+v", cfg)
+	}
+
+	if cfg.Options["topic"] != "subscription-events" {
+		t.Errorf("Options[topic] = %q, want %q", cfg.Options["topic"], "subscription-events")
+	}
+}
+
+func TestParseSinkConfigNATS(t *testing.T) {
+	cfg, err := ParseSinkConfig("nats:nats://nats.example.com:4222?subject=subscription-events")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Kind != SinkKindNATS || cfg.Options["subject"] != "subscription-events" {
+		t.Errorf("unexpected SinkConfig: %+v", cfg)
+	}
+}
+
+func TestParseSinkConfigMissingColon(t *testing.T) {
+	if _, err := ParseSinkConfig("not-a-sink"); err == nil {
+		t.Fatal("expected an error for a sink string with no <kind>:<target> separator")
+	}
+}
+
+func TestParseSinkConfigUnsupportedKind(t *testing.T) {
+	if _, err := ParseSinkConfig("carrier-pigeon:loft"); err == nil {
+		t.Fatal("expected an error for an unsupported sink kind")
+	}
+}
+
+func TestParseSinkConfigInvalidOption(t *testing.T) {
+	if _, err := ParseSinkConfig("http:https://example.com?not-a-kv-pair"); err == nil {
+		t.Fatal("expected an error for a malformed option")
+	}
+}
+
+func TestSinkConfigForPrefersAnnotationOverDefault(t *testing.T) {
+	annotations := map[string]string{AnnotationEventSink: "http:https://override.example.com"}
+
+	cfg, err := SinkConfigFor(annotations)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Target != "https://override.example.com" {
+		t.Errorf("Target = %q, want the annotation override", cfg.Target)
+	}
+}
+
+func TestSinkConfigForFallsBackToDefault(t *testing.T) {
+	cfg, err := SinkConfigFor(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Kind != SinkKindNone {
+		t.Errorf("Kind = %q, want %q when no annotation or flag is set", cfg.Kind, SinkKindNone)
+	}
+}
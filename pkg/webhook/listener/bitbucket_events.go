@@ -0,0 +1,197 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"k8s.io/klog"
+)
+
+const (
+	bitbucketEventKeyHeader  = "X-Event-Key"
+	bitbucketSignatureSHA256 = "X-Hub-Signature"
+	bitbucketHookUUIDHeader  = "X-Hook-UUID"
+)
+
+// bitbucketLink is the {"href": "..."} shape Bitbucket uses throughout its
+// "_links" objects.
+type bitbucketLink struct {
+	Href string `json:"href"`
+	Name string `json:"name,omitempty"`
+}
+
+// bitbucketServerRepository mirrors the subset of Bitbucket Server's
+// repository payload (wrapped in refs_changed events) needed to match a
+// channel.
+type bitbucketServerRepository struct {
+	Slug    string `json:"slug"`
+	Project struct {
+		Key string `json:"key"`
+	} `json:"project"`
+	Links struct {
+		Clone []bitbucketLink `json:"clone"`
+		Self  []bitbucketLink `json:"self"`
+	} `json:"links"`
+}
+
+type bitbucketServerRefsChangedEvent struct {
+	Repository bitbucketServerRepository `json:"repository"`
+	Changes    []struct {
+		RefID string `json:"refId"`
+	} `json:"changes"`
+}
+
+// bitbucketServerProvider parses Bitbucket Server's repo:refs_changed
+// payload. Signature verification is handled separately by a
+// SignatureVerifier.
+type bitbucketServerProvider struct{}
+
+func (p *bitbucketServerProvider) Parse(body []byte, r *http.Request) (*RepoEvent, error) {
+	if eventKey := r.Header.Get(bitbucketEventKeyHeader); eventKey != "repo:refs_changed" {
+		klog.Infof("Unhandled Bitbucket Server event type %s\n", eventKey)
+		return nil, nil
+	}
+
+	var e bitbucketServerRefsChangedEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, err
+	}
+
+	repoEvent := &RepoEvent{
+		FullName: e.Repository.Project.Key + "/" + e.Repository.Slug,
+		Provider: ProviderBitbucketServer,
+	}
+
+	for _, l := range e.Repository.Links.Clone {
+		if l.Name == "http" || repoEvent.CloneURL == "" {
+			repoEvent.CloneURL = l.Href
+		}
+	}
+
+	if len(e.Repository.Links.Self) > 0 {
+		repoEvent.HTMLURL = e.Repository.Links.Self[0].Href
+	}
+
+	if len(e.Changes) > 0 {
+		repoEvent.Ref = e.Changes[0].RefID
+	}
+
+	return repoEvent, nil
+}
+
+// bitbucketCloudRepository mirrors the subset of Bitbucket Cloud's
+// repository payload needed to match a channel.
+type bitbucketCloudRepository struct {
+	FullName string `json:"full_name"`
+	Links    struct {
+		HTML  bitbucketLink   `json:"html"`
+		Clone []bitbucketLink `json:"clone"`
+	} `json:"links"`
+}
+
+type bitbucketCloudPushEvent struct {
+	Repository bitbucketCloudRepository `json:"repository"`
+	Push       struct {
+		Changes []struct {
+			New struct {
+				Name string `json:"name"`
+			} `json:"new"`
+		} `json:"changes"`
+	} `json:"push"`
+}
+
+// bitbucketCloudProvider parses Bitbucket Cloud's repo:push payload,
+// identified by the presence of the X-Hook-UUID header. Verification of
+// that header is handled separately by a SignatureVerifier.
+type bitbucketCloudProvider struct{}
+
+func (p *bitbucketCloudProvider) Parse(body []byte, r *http.Request) (*RepoEvent, error) {
+	if eventKey := r.Header.Get(bitbucketEventKeyHeader); eventKey != "repo:push" {
+		klog.Infof("Unhandled Bitbucket Cloud event type %s\n", eventKey)
+		return nil, nil
+	}
+
+	var e bitbucketCloudPushEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, err
+	}
+
+	repoEvent := &RepoEvent{
+		CloneURL: e.Repository.Links.HTML.Href,
+		HTMLURL:  e.Repository.Links.HTML.Href,
+		FullName: e.Repository.FullName,
+		Provider: ProviderBitbucketCloud,
+	}
+
+	for _, l := range e.Repository.Links.Clone {
+		if l.Name == "https" {
+			repoEvent.CloneURL = l.Href
+		}
+	}
+
+	if len(e.Push.Changes) > 0 {
+		repoEvent.Ref = e.Push.Changes[0].New.Name
+	}
+
+	return repoEvent, nil
+}
+
+// bitbucketCloudVerifier compares Bitbucket Cloud's X-Hook-UUID header
+// against the configured secret, since Bitbucket Cloud has no HMAC
+// signature of its own.
+type bitbucketCloudVerifier struct{}
+
+func (bitbucketCloudVerifier) Verify(r *http.Request, body []byte, secret []byte) error {
+	if len(secret) == 0 {
+		return nil
+	}
+
+	uuid := strings.Trim(r.Header.Get(bitbucketHookUUIDHeader), "{}")
+	if subtle.ConstantTimeCompare([]byte(uuid), secret) != 1 {
+		return errors.New("bitbucket cloud hook UUID mismatch")
+	}
+
+	return nil
+}
+
+func validateHMACSHA256(signature string, body, secret []byte) error {
+	const prefix = "sha256="
+
+	if !strings.HasPrefix(signature, prefix) {
+		return errors.New("missing sha256= prefix on signature")
+	}
+
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, prefix))
+	if err != nil {
+		return err
+	}
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return errors.New("signature mismatch")
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"context"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog"
+
+	appv1alpha1 "github.com/IBM/multicloud-operators-subscription/pkg/apis/app/v1alpha1"
+	"github.com/IBM/multicloud-operators-subscription/pkg/events"
+)
+
+// hubEventSource identifies this listener as the CloudEvents "source"
+// attribute on every webhook-trigger event it publishes.
+const hubEventSource = "multicloud-operators-subscription/webhook"
+
+// defaultPublisher is resolved from the process-wide --events-sink flag the
+// first time it's needed, rather than at package-init time: WebhookListener
+// has no constructor in this package to thread the publisher through, and
+// flag.Parse() has not run yet when package-level var initializers execute.
+var (
+	defaultPublisherOnce sync.Once
+	defaultPublisher     *events.Publisher
+
+	// publisherCache memoizes the Publishers built for per-channel
+	// AnnotationEventSink overrides, so a Kafka/NATS override doesn't open a
+	// fresh producer/connection on every accepted webhook.
+	publisherCache events.PublisherCache
+)
+
+func defaultEventPublisher() *events.Publisher {
+	defaultPublisherOnce.Do(func() {
+		defaultPublisher = mustDefaultPublisher()
+	})
+
+	return defaultPublisher
+}
+
+func mustDefaultPublisher() *events.Publisher {
+	sink, err := events.DefaultSinkConfig()
+	if err != nil {
+		klog.Error("Invalid --events-sink configuration, disabling webhook CloudEvents. error: ", err)
+		sink = events.SinkConfig{Kind: events.SinkKindNone}
+	}
+
+	publisher, err := events.NewPublisher(sink, hubEventSource)
+	if err != nil {
+		klog.Error("Failed to build webhook CloudEvents publisher, events will not be published. error: ", err)
+		publisher, _ = events.NewPublisher(events.SinkConfig{Kind: events.SinkKindNone}, hubEventSource)
+	}
+
+	return publisher
+}
+
+// publishWebhookTrigger emits a CloudEvent recording that repoEvent
+// triggered a reconcile of sub. Publishing is best effort: a send error is
+// logged and never fails the webhook response, though the send is
+// synchronous and delays it. Like ReconcileSubscription.publishPhaseEvent
+// on the mcmhub side, sub's AnnotationEventSink overrides the process
+// default when present.
+func publishWebhookTrigger(sub appv1alpha1.Subscription, repoEvent *RepoEvent) {
+	subject := types.NamespacedName{Name: sub.GetName(), Namespace: sub.GetNamespace()}.String()
+
+	publisher := publisherCache.Get(sub.GetAnnotations(), hubEventSource, defaultEventPublisher())
+
+	if err := publisher.PublishWebhookTrigger(context.TODO(), subject, repoEvent); err != nil {
+		klog.Error("Failed to publish CloudEvent for webhook trigger on ", subject, ", error: ", err)
+	}
+}
+
+// webhookEventRecorder records Kubernetes Events for things the webhook
+// listener wants visible via `kubectl describe`, such as signature
+// verification failures. It is nil until SetEventRecorder is called from
+// manager setup; while nil, recordWebhookEvent does nothing.
+var webhookEventRecorder record.EventRecorder
+
+// SetEventRecorder wires in the recorder used for webhook-related
+// Kubernetes Events. Call this once from manager setup.
+func SetEventRecorder(recorder record.EventRecorder) {
+	webhookEventRecorder = recorder
+}
+
+func recordWebhookEvent(object runtime.Object, eventType, reason, message string) {
+	if webhookEventRecorder == nil {
+		return
+	}
+
+	webhookEventRecorder.Event(object, eventType, reason, message)
+}
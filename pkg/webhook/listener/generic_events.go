@@ -0,0 +1,57 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// genericPushEvent is the minimal JSON push envelope self-hosted Git servers
+// (that don't speak GitHub/GitLab/Bitbucket) can send:
+//
+//	{
+//	  "clone_url": "https://git.example.com/team/app.git",
+//	  "html_url":  "https://git.example.com/team/app",
+//	  "full_name": "team/app",
+//	  "ref":       "refs/heads/main"
+//	}
+type genericPushEvent struct {
+	CloneURL string `json:"clone_url"`
+	HTMLURL  string `json:"html_url"`
+	FullName string `json:"full_name"`
+	Ref      string `json:"ref"`
+}
+
+// genericProvider parses the generic JSON push envelope. Signature
+// validation for generic channels is delegated to the SignatureVerifier
+// configured on the channel, since self-hosted servers vary widely in how
+// they sign requests.
+type genericProvider struct{}
+
+func (p *genericProvider) Parse(body []byte, r *http.Request) (*RepoEvent, error) {
+	var e genericPushEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, err
+	}
+
+	return &RepoEvent{
+		CloneURL: e.CloneURL,
+		HTMLURL:  e.HTMLURL,
+		FullName: e.FullName,
+		Ref:      e.Ref,
+		Provider: ProviderGeneric,
+	}, nil
+}
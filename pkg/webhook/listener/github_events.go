@@ -22,7 +22,6 @@ import (
 	"net/url"
 	"strings"
 
-	"github.com/ghodss/yaml"
 	"github.com/google/go-github/v28/github"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,21 +39,24 @@ const (
 	signatureHeader  = "X-Hub-Signature"
 )
 
+// handleGithubWebhook is the single entry point for all incoming Git webhook
+// requests, despite the name it is provider-agnostic: it reads the raw
+// request once, determines the originating provider, and normalizes the
+// payload into a RepoEvent before matching it against subscriptions. The
+// name is kept for the HTTP route that has always called it.
 func (listener *WebhookListener) handleGithubWebhook(r *http.Request) error {
-	var body []byte
-
-	var signature string
-
-	var event interface{}
-
-	var err error
+	if !IsReady() {
+		return errors.New("webhook listener is not ready, hub cache has not synced yet")
+	}
 
-	body, signature, event, err = listener.ParseRequest(r)
+	body, err := readRequestBody(r)
 	if err != nil {
-		klog.Error("Failed to parse the request. error:", err)
+		klog.Error("Failed to read the request body. error: ", err)
 		return err
 	}
 
+	detected := detectProvider(r)
+
 	subList := &appv1alpha1.SubscriptionList{}
 	listopts := &client.ListOptions{}
 
@@ -70,7 +72,6 @@ func (listener *WebhookListener) handleGithubWebhook(r *http.Request) error {
 
 		chNamespace := ""
 		chName := ""
-		chType := ""
 
 		if sub.Spec.Channel != "" {
 			strs := strings.Split(sub.Spec.Channel, "/")
@@ -87,132 +88,133 @@ func (listener *WebhookListener) handleGithubWebhook(r *http.Request) error {
 		chobj := &chnv1alpha1.Channel{}
 		err := listener.RemoteClient.Get(context.TODO(), chkey, chobj)
 
-		if err == nil {
-			chType = string(chobj.Spec.Type)
-		} else {
+		if err != nil {
 			klog.Error("Failed to get subscription's channel. error: ", err)
 			continue
 		}
 
+		annotations := chobj.GetAnnotations()
+		providerName := providerForChannel(annotations, detected)
+
 		// This WebHook event is applicable for this subscription if:
-		// 		1. channel type is github
-		// 		2. AND ValidateSignature is true with the channel's secret token
-		// 		3. AND channel path contains the repo full name from the event
+		// 		1. the channel is a Git-flavored channel
+		// 		2. AND the channel is configured for (or its headers match) this provider
+		// 		3. AND signature/token validation succeeds against the channel's secret
+		// 		4. AND channel path contains the repo full name from the event
 		// If these conditions are not met, skip to the next subscription.
 
-		if !strings.EqualFold(chType, chnv1alpha1.ChannelTypeGitHub) {
-			klog.V(2).Infof("The channel type is %s. Skipping to process this subscription.", chType)
+		if !isGitChannel(chobj) {
+			klog.V(2).Infof("The channel type is %s. Skipping to process this subscription.", chobj.Spec.Type)
 			continue
 		}
 
-		if signature != "" {
-			if !listener.validateSecret(signature, chobj.GetAnnotations(), chNamespace, body) {
-				continue
-			}
+		provider, err := providerByName(providerName)
+		if err != nil {
+			klog.Info("Skipping subscription with unsupported webhook provider: ", err)
+			continue
 		}
 
-		switch e := event.(type) {
-		case *github.PullRequestEvent:
-			if chobj.Spec.PathName == e.GetRepo().GetCloneURL() ||
-				chobj.Spec.PathName == e.GetRepo().GetHTMLURL() ||
-				chobj.Spec.PathName == e.GetRepo().GetURL() ||
-				strings.Contains(chobj.Spec.PathName, e.GetRepo().GetFullName()) {
-				klog.Info("Processing PUSH event from " + e.GetRepo().GetHTMLURL())
-				listener.updateSubscription(sub)
-			}
-		case *github.PushEvent:
-			if chobj.Spec.PathName == e.GetRepo().GetCloneURL() ||
-				chobj.Spec.PathName == e.GetRepo().GetHTMLURL() ||
-				chobj.Spec.PathName == e.GetRepo().GetURL() ||
-				strings.Contains(chobj.Spec.PathName, e.GetRepo().GetFullName()) {
-				klog.Info("Processing PUSH event from " + e.GetRepo().GetHTMLURL())
-				listener.updateSubscription(sub)
-			}
-		default:
-			klog.Infof("Unhandled event type %s\n", github.WebHookType(r))
+		secret := listener.loadChannelSecret(annotations, chNamespace)
+
+		if err := verifierForProvider(annotations, providerName).Verify(r, body, secret); err != nil {
+			klog.Info("Failed to verify webhook signature for channel ", chobj.GetName(), ", error: ", err)
+			listener.recordSignatureFailure(chobj, err)
+			webhookSignatureFailuresTotal.Inc()
+			webhookEventsTotal.WithLabelValues(providerName, "signature_failed").Inc()
+
+			continue
+		}
+
+		repoEvent, err := provider.Parse(body, r)
+		if err != nil {
+			klog.Info("Failed to parse webhook event for provider ", providerName, ", error: ", err)
+			webhookEventsTotal.WithLabelValues(providerName, "parse_error").Inc()
+
+			continue
+		}
+
+		if repoEvent == nil {
+			klog.V(2).Infof("Unhandled %s event, nothing to reconcile", providerName)
+			webhookEventsTotal.WithLabelValues(providerName, "unhandled").Inc()
+
 			continue
 		}
+
+		if !repoEvent.Matches(chobj.Spec.PathName) {
+			webhookEventsTotal.WithLabelValues(providerName, "unmatched").Inc()
+			continue
+		}
+
+		klog.Info("Processing " + repoEvent.Provider + " push event from " + repoEvent.HTMLURL)
+		webhookEventsTotal.WithLabelValues(providerName, "accepted").Inc()
+		publishWebhookTrigger(sub, repoEvent)
+		listener.updateSubscription(sub)
 	}
 
 	return nil
 }
 
-// ParseRequest parses incoming WebHook event request
-func (listener *WebhookListener) ParseRequest(r *http.Request) (body []byte, signature string, event interface{}, err error) {
-	var payload []byte
+func readRequestBody(r *http.Request) ([]byte, error) {
+	defer r.Body.Close()
 
 	switch contentType := r.Header.Get("Content-Type"); contentType {
 	case "application/json":
-		if body, err = ioutil.ReadAll(r.Body); err != nil {
-			klog.Error("Failed to read the request body. error: ", err)
-			return nil, "", nil, err
-		}
-
-		payload = body //the JSON payload
+		return ioutil.ReadAll(r.Body)
 	case "application/x-www-form-urlencoded":
-		if body, err = ioutil.ReadAll(r.Body); err != nil {
-			klog.Error("Failed to read the request body. error: ", err)
-			return nil, "", nil, err
+		raw, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
 		}
 
-		form, err := url.ParseQuery(string(body))
+		form, err := url.ParseQuery(string(raw))
 		if err != nil {
-			klog.Error("Failed to parse the request body. error: ", err)
-			return nil, "", nil, err
+			return nil, err
 		}
 
-		payload = []byte(form.Get(payloadFormParam))
+		return []byte(form.Get(payloadFormParam)), nil
 	default:
-		klog.Warningf("Webhook request has unsupported Content-Type %q", contentType)
-		return nil, "", nil, errors.New("Unsupported Content-Type: " + contentType)
+		return nil, errors.New("Unsupported Content-Type: " + contentType)
 	}
+}
 
-	defer r.Body.Close()
+// recordSignatureFailure surfaces a webhook signature/token verification
+// failure as a Kubernetes Event on the channel, in addition to the klog
+// line callers already log, so it's visible via `kubectl describe channel`
+// instead of only in controller logs.
+func (listener *WebhookListener) recordSignatureFailure(chobj *chnv1alpha1.Channel, reason error) {
+	recordWebhookEvent(chobj, corev1.EventTypeWarning, "WebhookSignatureVerificationFailed",
+		"channel "+chobj.GetName()+": "+reason.Error())
+}
 
-	signature = r.Header.Get(signatureHeader)
+// githubProvider parses GitHub's PushEvent/PullRequestEvent payloads.
+// Signature verification is handled separately by a SignatureVerifier.
+type githubProvider struct{}
 
-	event, err = github.ParseWebHook(github.WebHookType(r), payload)
+func (p *githubProvider) Parse(body []byte, r *http.Request) (*RepoEvent, error) {
+	event, err := github.ParseWebHook(github.WebHookType(r), body)
 	if err != nil {
-		klog.Error("could not parse webhook. error:", err)
-		return nil, "", nil, err
+		return nil, err
 	}
 
-	return body, signature, event, nil
-}
-
-func (listener *WebhookListener) validateSecret(signature string, annotations map[string]string, chNamespace string, body []byte) (ret bool) {
-	secret := ""
-	ret = true
-	// Get GitHub WebHook secret from the channel annotations
-	if annotations["webhook-secret"] == "" {
-		klog.Info("No webhook secret found in annotations")
-
-		ret = false
-	} else {
-		seckey := types.NamespacedName{Name: annotations["webhook-secret"], Namespace: chNamespace}
-		secobj := &corev1.Secret{}
-
-		err := listener.RemoteClient.Get(context.TODO(), seckey, secobj)
-		if err != nil {
-			klog.Info("Failed to get secret for channel webhook listener, error: ", err)
-			ret = false
-		}
-
-		err = yaml.Unmarshal(secobj.Data["secret"], &secret)
-		if err != nil {
-			klog.Info("Failed to unmarshal secret from the webhook secret. Skip this subscription, error: ", err)
-			ret = false
-		} else if secret == "" {
-			klog.Info("Failed to get secret from the webhook secret. Skip this subscription, error: ", err)
-			ret = false
-		}
-	}
-	// Using the channel's webhook secret, validate it against the request's body
-	if err := github.ValidateSignature(signature, body, []byte(secret)); err != nil {
-		klog.Info("Failed to validate webhook event signature, error: ", err)
-		// If validation fails, this webhook event is not for this subscription. Skip.
-		ret = false
+	switch e := event.(type) {
+	case *github.PushEvent:
+		return &RepoEvent{
+			CloneURL: e.GetRepo().GetCloneURL(),
+			HTMLURL:  e.GetRepo().GetHTMLURL(),
+			FullName: e.GetRepo().GetFullName(),
+			Ref:      e.GetRef(),
+			Provider: ProviderGitHub,
+		}, nil
+	case *github.PullRequestEvent:
+		return &RepoEvent{
+			CloneURL: e.GetRepo().GetCloneURL(),
+			HTMLURL:  e.GetRepo().GetHTMLURL(),
+			FullName: e.GetRepo().GetFullName(),
+			Ref:      e.GetPullRequest().GetHead().GetRef(),
+			Provider: ProviderGitHub,
+		}, nil
+	default:
+		klog.Infof("Unhandled GitHub event type %s\n", github.WebHookType(r))
+		return nil, nil
 	}
-
-	return ret
 }
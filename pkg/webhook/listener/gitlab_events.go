@@ -0,0 +1,82 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"k8s.io/klog"
+)
+
+const gitlabTokenHeader = "X-Gitlab-Token"
+
+// gitlabProject is the subset of GitLab's project payload shared by the
+// Push Hook and Merge Request Hook events.
+type gitlabProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	GitHTTPURL        string `json:"git_http_url"`
+	WebURL            string `json:"web_url"`
+}
+
+type gitlabPushEvent struct {
+	Ref     string        `json:"ref"`
+	Project gitlabProject `json:"project"`
+}
+
+type gitlabMergeRequestEvent struct {
+	Project          gitlabProject `json:"project"`
+	ObjectAttributes struct {
+		SourceBranch string `json:"source_branch"`
+	} `json:"object_attributes"`
+}
+
+// gitlabProvider parses GitLab's Push Hook and Merge Request Hook payloads.
+// Token verification is handled separately by a SignatureVerifier.
+type gitlabProvider struct{}
+
+func (p *gitlabProvider) Parse(body []byte, r *http.Request) (*RepoEvent, error) {
+	switch eventType := r.Header.Get("X-Gitlab-Event"); eventType {
+	case "Push Hook":
+		var e gitlabPushEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, err
+		}
+
+		return &RepoEvent{
+			CloneURL: e.Project.GitHTTPURL,
+			HTMLURL:  e.Project.WebURL,
+			FullName: e.Project.PathWithNamespace,
+			Ref:      e.Ref,
+			Provider: ProviderGitLab,
+		}, nil
+	case "Merge Request Hook":
+		var e gitlabMergeRequestEvent
+		if err := json.Unmarshal(body, &e); err != nil {
+			return nil, err
+		}
+
+		return &RepoEvent{
+			CloneURL: e.Project.GitHTTPURL,
+			HTMLURL:  e.Project.WebURL,
+			FullName: e.Project.PathWithNamespace,
+			Ref:      e.ObjectAttributes.SourceBranch,
+			Provider: ProviderGitLab,
+		}, nil
+	default:
+		klog.Infof("Unhandled GitLab event type %s\n", eventType)
+		return nil, nil
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import "testing"
+
+func TestFindKeyByKid(t *testing.T) {
+	keys := jwks{Keys: []jwk{{Kid: "a"}, {Kid: "b"}}}
+
+	got, err := findKey(keys, "b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Kid != "b" {
+		t.Errorf("findKey selected kid %q, want %q", got.Kid, "b")
+	}
+}
+
+func TestFindKeyUnknownKid(t *testing.T) {
+	keys := jwks{Keys: []jwk{{Kid: "a"}, {Kid: "b"}}}
+
+	if _, err := findKey(keys, "missing"); err == nil {
+		t.Fatal("expected an error for a kid with no matching key")
+	}
+}
+
+func TestFindKeyNoKidSingleKey(t *testing.T) {
+	keys := jwks{Keys: []jwk{{Kid: "only"}}}
+
+	got, err := findKey(keys, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got.Kid != "only" {
+		t.Errorf("findKey returned kid %q, want %q", got.Kid, "only")
+	}
+}
+
+// TestFindKeyNoKidMultipleKeysIsAmbiguous is the rotation scenario this
+// verifier must not guess through: with more than one key published and no
+// kid on the token, there is no correct way to pick the one that actually
+// signed it.
+func TestFindKeyNoKidMultipleKeysIsAmbiguous(t *testing.T) {
+	keys := jwks{Keys: []jwk{{Kid: "a"}, {Kid: "b"}}}
+
+	if _, err := findKey(keys, ""); err == nil {
+		t.Fatal("expected an error for a kid-less token against a multi-key JWKS")
+	}
+}
+
+func TestFindKeyEmptyJWKS(t *testing.T) {
+	if _, err := findKey(jwks{}, ""); err == nil {
+		t.Fatal("expected an error for an empty key set")
+	}
+}
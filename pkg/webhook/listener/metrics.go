@@ -0,0 +1,36 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	webhookEventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_events_total",
+		Help: "Total number of webhook requests handled, by provider and result (accepted, unmatched, unhandled, parse_error, signature_failed).",
+	}, []string{"provider", "result"})
+
+	webhookSignatureFailuresTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "webhook_signature_failures_total",
+		Help: "Total number of webhook requests rejected for failing signature/token verification.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(webhookEventsTotal, webhookSignatureFailuresTotal)
+}
@@ -0,0 +1,127 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	chnv1alpha1 "github.com/IBM/multicloud-operators-channel/pkg/apis/app/v1alpha1"
+)
+
+// webhookProviderAnnotation lets a channel pin the Git provider a webhook
+// payload should be parsed as, instead of relying purely on header sniffing.
+const webhookProviderAnnotation = "webhook-type"
+
+// Supported values for the webhook-type annotation and for RepoEvent.Provider.
+const (
+	ProviderGitHub          = "github"
+	ProviderGitLab          = "gitlab"
+	ProviderBitbucketServer = "bitbucket-server"
+	ProviderBitbucketCloud  = "bitbucket-cloud"
+	ProviderGeneric         = "generic"
+)
+
+// RepoEvent is a normalized view of a push (or push-like) event coming from
+// any of the supported Git providers, so the subscription-matching code
+// downstream does not need to know which provider produced it.
+type RepoEvent struct {
+	CloneURL string
+	HTMLURL  string
+	FullName string
+	Ref      string
+	Provider string
+}
+
+// Matches reports whether the event's repository identifies the channel
+// pointed to by pathName, mirroring the github.com/.../.git URL matching
+// that the original GitHub-only listener performed.
+func (e *RepoEvent) Matches(pathName string) bool {
+	if e == nil || pathName == "" {
+		return false
+	}
+
+	return pathName == e.CloneURL ||
+		pathName == e.HTMLURL ||
+		(e.FullName != "" && strings.Contains(pathName, e.FullName))
+}
+
+// webhookProvider knows how to normalize a single Git host's webhook
+// payload.
+type webhookProvider interface {
+	// Parse extracts the normalized RepoEvent from an already-verified
+	// request. A nil RepoEvent with a nil error means the event type is
+	// not one subscriptions care about (e.g. a ping event). Signature/token
+	// verification is a separate concern, handled by a SignatureVerifier.
+	Parse(body []byte, r *http.Request) (*RepoEvent, error)
+}
+
+// detectProvider identifies the Git host that sent the request from its
+// well-known headers. Channels may override this via the webhook-type
+// annotation, since some self-hosted servers reuse generic header names.
+func detectProvider(r *http.Request) string {
+	switch {
+	case r.Header.Get("X-Gitlab-Event") != "" || r.Header.Get("X-Gitlab-Token") != "":
+		return ProviderGitLab
+	case r.Header.Get("X-Hook-UUID") != "":
+		return ProviderBitbucketCloud
+	case r.Header.Get("X-Event-Key") != "" && strings.HasPrefix(r.Header.Get("X-Event-Key"), "repo:"):
+		return ProviderBitbucketServer
+	case r.Header.Get("X-GitHub-Event") != "":
+		return ProviderGitHub
+	default:
+		return ProviderGeneric
+	}
+}
+
+// providerForChannel resolves which provider a given channel expects,
+// preferring the explicit annotation over header sniffing so operators can
+// disambiguate self-hosted servers that mimic another provider's headers.
+func providerForChannel(annotations map[string]string, detected string) string {
+	if wt, ok := annotations[webhookProviderAnnotation]; ok && wt != "" {
+		return strings.ToLower(wt)
+	}
+
+	return detected
+}
+
+// isGitChannel reports whether chobj is a Git-flavored channel at all,
+// regardless of which Git host's webhook is being matched against it. The
+// original GitHub-only listener required ChannelTypeGitHub before doing
+// anything; gating every provider (not just GitHub) on the same check keeps
+// non-Git channels (HelmRepo, ObjectBucket, ...) from being eligible for
+// webhook-triggered reconciles purely via the webhook-type annotation and a
+// PathName substring match.
+func isGitChannel(chobj *chnv1alpha1.Channel) bool {
+	return strings.EqualFold(string(chobj.Spec.Type), chnv1alpha1.ChannelTypeGitHub)
+}
+
+func providerByName(name string) (webhookProvider, error) {
+	switch name {
+	case ProviderGitHub:
+		return &githubProvider{}, nil
+	case ProviderGitLab:
+		return &gitlabProvider{}, nil
+	case ProviderBitbucketServer:
+		return &bitbucketServerProvider{}, nil
+	case ProviderBitbucketCloud:
+		return &bitbucketCloudProvider{}, nil
+	case ProviderGeneric:
+		return &genericProvider{}, nil
+	default:
+		return nil, errors.New("unsupported webhook provider: " + name)
+	}
+}
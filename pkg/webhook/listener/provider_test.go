@@ -0,0 +1,153 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	chnv1alpha1 "github.com/IBM/multicloud-operators-channel/pkg/apis/app/v1alpha1"
+)
+
+func TestRepoEventMatches(t *testing.T) {
+	e := &RepoEvent{
+		CloneURL: "https://github.com/acme/widgets.git",
+		HTMLURL:  "https://github.com/acme/widgets",
+		FullName: "acme/widgets",
+	}
+
+	tests := []struct {
+		name     string
+		pathName string
+		want     bool
+	}{
+		{"matches clone URL", "https://github.com/acme/widgets.git", true},
+		{"matches HTML URL", "https://github.com/acme/widgets", true},
+		{"matches full name substring", "https://ghe.example.com/acme/widgets.git", true},
+		{"no match", "https://github.com/other/repo.git", false},
+		{"empty path name", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := e.Matches(tt.pathName); got != tt.want {
+				t.Errorf("Matches(%q) = %v, want %v", tt.pathName, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepoEventMatchesNilEvent(t *testing.T) {
+	var e *RepoEvent
+	if e.Matches("anything") {
+		t.Error("a nil RepoEvent must never match")
+	}
+}
+
+func TestDetectProvider(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   string
+	}{
+		{"gitlab event header", "X-Gitlab-Event", "Push Hook", ProviderGitLab},
+		{"bitbucket cloud hook uuid", "X-Hook-UUID", "abc-123", ProviderBitbucketCloud},
+		{"bitbucket server event key", "X-Event-Key", "repo:refs_changed", ProviderBitbucketServer},
+		{"github event header", "X-GitHub-Event", "push", ProviderGitHub},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set(tt.header, tt.value)
+
+			if got := detectProvider(req); got != tt.want {
+				t.Errorf("detectProvider() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no known headers falls back to generic", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+
+		if got := detectProvider(req); got != ProviderGeneric {
+			t.Errorf("detectProvider() = %q, want %q", got, ProviderGeneric)
+		}
+	})
+}
+
+func TestIsGitChannel(t *testing.T) {
+	gitChannel := &chnv1alpha1.Channel{}
+	gitChannel.Spec.Type = chnv1alpha1.ChannelTypeGitHub
+
+	if !isGitChannel(gitChannel) {
+		t.Error("a channel of ChannelTypeGitHub must be treated as a Git channel")
+	}
+
+	nonGitChannel := &chnv1alpha1.Channel{}
+	nonGitChannel.Spec.Type = "HelmRepo"
+
+	if isGitChannel(nonGitChannel) {
+		t.Error("a non-Git channel type must not be treated as a Git channel")
+	}
+}
+
+func TestGithubProviderParsePushEvent(t *testing.T) {
+	body := []byte(`{
+		"ref": "refs/heads/main",
+		"repository": {
+			"full_name": "acme/widgets",
+			"clone_url": "https://github.com/acme/widgets.git",
+			"html_url": "https://github.com/acme/widgets"
+		}
+	}`)
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-GitHub-Event", "push")
+
+	p := &githubProvider{}
+
+	got, err := p.Parse(body, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got == nil {
+		t.Fatal("expected a non-nil RepoEvent for a push event")
+	}
+
+	if got.Ref != "refs/heads/main" || !strings.EqualFold(got.Provider, ProviderGitHub) {
+		t.Errorf("unexpected RepoEvent: %+v", got)
+	}
+}
+
+func TestGithubProviderParseUnhandledEvent(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("X-GitHub-Event", "ping")
+
+	p := &githubProvider{}
+
+	got, err := p.Parse([]byte(`{}`), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got != nil {
+		t.Errorf("expected a nil RepoEvent for an unhandled event type, got: %+v", got)
+	}
+}
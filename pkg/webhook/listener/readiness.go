@@ -0,0 +1,40 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import "sync/atomic"
+
+// ready is flipped to 1 once the manager reports its cache has synced.
+// Until then the listener rejects webhook requests instead of evaluating
+// them against a Subscription/Channel cache that may still be empty.
+var ready int32
+
+// SetReady marks the webhook listener ready (or not) to accept traffic.
+// Call this from the manager's cache-sync callback, e.g.
+// mgr.GetCache().WaitForCacheSync() followed by SetReady(true).
+func SetReady(r bool) {
+	if r {
+		atomic.StoreInt32(&ready, 1)
+	} else {
+		atomic.StoreInt32(&ready, 0)
+	}
+}
+
+// IsReady reports whether the listener is ready to accept webhook traffic.
+// Wire this into the HTTP server's readiness probe and/or have it reject
+// requests directly, as handleGithubWebhook does.
+func IsReady() bool {
+	return atomic.LoadInt32(&ready) == 1
+}
@@ -0,0 +1,129 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+)
+
+// Annotations naming where a channel's webhook secret comes from. Exactly
+// one should be set; secretRef is checked first, so it wins if more than
+// one is present.
+const (
+	// secretRefAnnotation names a Secret (optionally "name/key", key
+	// defaults to "secret") in the channel's namespace.
+	secretRefAnnotation = "secretRef"
+	// csiSecretRefAnnotation names a file path a CSI secrets-store volume
+	// projects the secret into, for operators who don't want the secret
+	// re-encoded as a Kubernetes Secret at all.
+	csiSecretRefAnnotation = "csiSecretRef"
+	// envRefAnnotation names an environment variable on the operator's own
+	// pod holding the secret.
+	envRefAnnotation = "envRef"
+	// legacyWebhookSecretAnnotation is the original GitHub-only annotation,
+	// kept for backward compatibility. It behaves like secretRefAnnotation
+	// with the key hard-coded to "secret".
+	legacyWebhookSecretAnnotation = "webhook-secret"
+)
+
+// loadChannelSecret resolves the webhook secret for a channel from
+// whichever source its annotations configure. It returns nil (not an
+// error) when no source is configured or the lookup fails, which callers
+// treat as "skip signature validation" -- the same behavior the original
+// GitHub-only listener had for a missing secret.
+func (listener *WebhookListener) loadChannelSecret(annotations map[string]string, chNamespace string) []byte {
+	if ref := annotations[secretRefAnnotation]; ref != "" {
+		return listener.loadSecretRef(ref, chNamespace)
+	}
+
+	if path := annotations[csiSecretRefAnnotation]; path != "" {
+		return loadCSISecretRef(path)
+	}
+
+	if envName := annotations[envRefAnnotation]; envName != "" {
+		return loadEnvRef(envName)
+	}
+
+	if name := annotations[legacyWebhookSecretAnnotation]; name != "" {
+		return listener.loadSecretRef(name, chNamespace)
+	}
+
+	klog.Info("No webhook secret source found in annotations")
+
+	return nil
+}
+
+// loadSecretRef fetches a Secret named by ref ("name" or "name/key", key
+// defaulting to "secret") from chNamespace.
+func (listener *WebhookListener) loadSecretRef(ref, chNamespace string) []byte {
+	name := ref
+	key := "secret"
+
+	if idx := strings.LastIndex(ref, "/"); idx != -1 {
+		name, key = ref[:idx], ref[idx+1:]
+	}
+
+	seckey := types.NamespacedName{Name: name, Namespace: chNamespace}
+	secobj := &corev1.Secret{}
+
+	if err := listener.RemoteClient.Get(context.TODO(), seckey, secobj); err != nil {
+		klog.Info("Failed to get secret for channel webhook listener, error: ", err)
+		return nil
+	}
+
+	if raw, ok := secobj.Data[key]; ok {
+		// The legacy webhook-secret annotation stored the secret as a
+		// YAML-quoted string rather than a raw value; unmarshal to stay
+		// compatible with secrets created that way.
+		var decoded string
+		if err := yaml.Unmarshal(raw, &decoded); err == nil && decoded != "" {
+			return []byte(decoded)
+		}
+
+		return raw
+	}
+
+	klog.Info("Secret ", seckey, " has no key ", key)
+
+	return nil
+}
+
+func loadCSISecretRef(path string) []byte {
+	raw, err := ioutil.ReadFile(path) //nolint:gosec // path is operator-configured via channel annotation
+	if err != nil {
+		klog.Info("Failed to read CSI-projected webhook secret at ", path, ", error: ", err)
+		return nil
+	}
+
+	return raw
+}
+
+func loadEnvRef(name string) []byte {
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		klog.Info("Webhook secret environment variable ", name, " is not set")
+		return nil
+	}
+
+	return []byte(value)
+}
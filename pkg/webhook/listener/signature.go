@@ -0,0 +1,135 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/google/go-github/v28/github"
+)
+
+// signatureVerifierAnnotation lets a channel pick its SignatureVerifier
+// explicitly, instead of inferring one from the detected webhook provider.
+const signatureVerifierAnnotation = "webhook-signature-verifier"
+
+// SignatureVerifier validates that an incoming webhook request really came
+// from the Git host it claims to, using the channel's configured secret.
+// Implementations return nil only when the request is authentic.
+type SignatureVerifier interface {
+	Verify(r *http.Request, body []byte, secret []byte) error
+}
+
+// verifierForProvider returns the SignatureVerifier a given provider uses
+// by default, unless overridden by signatureVerifierAnnotation.
+func verifierForProvider(annotations map[string]string, providerName string) SignatureVerifier {
+	switch annotations[signatureVerifierAnnotation] {
+	case "github-sha1":
+		return githubSHA1Verifier{}
+	case "github-sha256":
+		return githubSHA256Verifier{}
+	case "gitlab-token":
+		return gitlabTokenVerifier{}
+	case "bitbucket-sha256":
+		return bitbucketSHA256Verifier{}
+	case "jwt":
+		return jwtVerifier{jwksURL: annotations[jwksURLAnnotation]}
+	}
+
+	switch providerName {
+	case ProviderGitHub:
+		return githubSHA1Verifier{}
+	case ProviderGitLab:
+		return gitlabTokenVerifier{}
+	case ProviderBitbucketServer:
+		return bitbucketSHA256Verifier{}
+	case ProviderBitbucketCloud:
+		return bitbucketCloudVerifier{}
+	default:
+		return noopVerifier{}
+	}
+}
+
+// githubSHA1Verifier validates GitHub's original X-Hub-Signature HMAC-SHA1 header.
+type githubSHA1Verifier struct{}
+
+func (githubSHA1Verifier) Verify(r *http.Request, body []byte, secret []byte) error {
+	if len(secret) == 0 {
+		return nil
+	}
+
+	signature := r.Header.Get(signatureHeader)
+	if signature == "" {
+		return errors.New("missing " + signatureHeader + " header")
+	}
+
+	return github.ValidateSignature(signature, body, secret)
+}
+
+// githubSHA256Verifier validates GitHub's newer X-Hub-Signature-256 HMAC-SHA256 header.
+type githubSHA256Verifier struct{}
+
+func (githubSHA256Verifier) Verify(r *http.Request, body []byte, secret []byte) error {
+	if len(secret) == 0 {
+		return nil
+	}
+
+	signature := r.Header.Get("X-Hub-Signature-256")
+	if signature == "" {
+		return errors.New("missing X-Hub-Signature-256 header")
+	}
+
+	return validateHMACSHA256(signature, body, secret)
+}
+
+// gitlabTokenVerifier compares GitLab's X-Gitlab-Token shared secret.
+type gitlabTokenVerifier struct{}
+
+func (gitlabTokenVerifier) Verify(r *http.Request, body []byte, secret []byte) error {
+	if len(secret) == 0 {
+		return nil
+	}
+
+	token := r.Header.Get(gitlabTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(token), secret) != 1 {
+		return errors.New("gitlab webhook token mismatch")
+	}
+
+	return nil
+}
+
+// bitbucketSHA256Verifier validates Bitbucket Server's X-Hub-Signature HMAC-SHA256 header.
+type bitbucketSHA256Verifier struct{}
+
+func (bitbucketSHA256Verifier) Verify(r *http.Request, body []byte, secret []byte) error {
+	if len(secret) == 0 {
+		return nil
+	}
+
+	signature := r.Header.Get(bitbucketSignatureSHA256)
+	if signature == "" {
+		return errors.New("missing " + bitbucketSignatureSHA256 + " header")
+	}
+
+	return validateHMACSHA256(signature, body, secret)
+}
+
+// noopVerifier accepts every request. It backs the generic provider, and
+// any other provider name verifierForProvider does not know, since those
+// authenticate some other way.
+type noopVerifier struct{}
+
+func (noopVerifier) Verify(*http.Request, []byte, []byte) error { return nil }
@@ -0,0 +1,115 @@
+// Copyright 2020 The Kubernetes Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package listener
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestSignatureVerifiersFailClosed locks in the behavior a secret-bearing
+// channel requires: a missing signature/token header must be rejected, not
+// treated as a successful verification. Only the no-secret-configured case
+// is allowed to skip verification.
+func TestSignatureVerifiersFailClosed(t *testing.T) {
+	secret := []byte("s3cret")
+
+	tests := []struct {
+		name     string
+		verifier SignatureVerifier
+	}{
+		{"githubSHA1", githubSHA1Verifier{}},
+		{"githubSHA256", githubSHA256Verifier{}},
+		{"bitbucketSHA256", bitbucketSHA256Verifier{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+
+			if err := tt.verifier.Verify(req, []byte("payload"), secret); err == nil {
+				t.Fatalf("%s: expected an error when the signature header is missing but a secret is configured", tt.name)
+			}
+		})
+	}
+}
+
+// TestSignatureVerifiersSkipWithNoSecret ensures the fail-closed fix above
+// didn't regress the legitimate skip case: a channel with no secret
+// configured at all has nothing to verify against.
+func TestSignatureVerifiersSkipWithNoSecret(t *testing.T) {
+	tests := []struct {
+		name     string
+		verifier SignatureVerifier
+	}{
+		{"githubSHA1", githubSHA1Verifier{}},
+		{"githubSHA256", githubSHA256Verifier{}},
+		{"gitlabToken", gitlabTokenVerifier{}},
+		{"bitbucketSHA256", bitbucketSHA256Verifier{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+
+			if err := tt.verifier.Verify(req, []byte("payload"), nil); err != nil {
+				t.Fatalf("%s: expected no error with no secret configured, got: %v", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestGitlabTokenVerifierMismatch(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(gitlabTokenHeader, "wrong-token")
+
+	if err := (gitlabTokenVerifier{}).Verify(req, []byte("payload"), []byte("expected-token")); err == nil {
+		t.Fatal("expected a token mismatch error")
+	}
+}
+
+func TestGitlabTokenVerifierMatch(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(gitlabTokenHeader, "expected-token")
+
+	if err := (gitlabTokenVerifier{}).Verify(req, []byte("payload"), []byte("expected-token")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestBitbucketCloudVerifierMismatch(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(bitbucketHookUUIDHeader, "{wrong-uuid}")
+
+	if err := (bitbucketCloudVerifier{}).Verify(req, []byte("payload"), []byte("expected-uuid")); err == nil {
+		t.Fatal("expected a hook UUID mismatch error")
+	}
+}
+
+func TestBitbucketCloudVerifierMatch(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set(bitbucketHookUUIDHeader, "{expected-uuid}")
+
+	if err := (bitbucketCloudVerifier{}).Verify(req, []byte("payload"), []byte("expected-uuid")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}
+
+func TestNoopVerifierAlwaysAccepts(t *testing.T) {
+	if err := (noopVerifier{}).Verify(nil, nil, []byte("secret")); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+}